@@ -0,0 +1,319 @@
+package instant
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// AdSize is ad width and height in pixels
+type AdSize struct {
+	Width  int
+	Height int
+}
+
+// Common ad sizes, Audience Network supports only AdSizeBanner and AdSizeMediumRectangle
+var (
+	AdSizeBanner          = AdSize{320, 50}
+	AdSizeLargeBanner     = AdSize{320, 100}
+	AdSizeMediumRectangle = AdSize{300, 250}
+)
+
+// errAutomaticAds is returned when ad is placed manually in article with automatic ad placement
+var errAutomaticAds = errors.New("Ads can't be placed in content of article with automatic ad placement")
+
+// AdSlot describes single ad. Ad is loaded from Src or rendered from inline Code.
+// If both are empty and PlacementID is set, Audience Network ad is requested.
+type AdSlot struct {
+	PlacementID string // Audience Network placement ID
+	Size        AdSize
+	Style       string // iframe style, e.g. border:0; margin:0;
+	Src         string // ad url
+	Code        string // inline ad code
+}
+
+// check returns error if ad slot has no ad or if Audience Network ad is invalid
+func (s AdSlot) check() error {
+	if s.Src != "" || s.Code != "" {
+		return nil
+	}
+	if s.PlacementID == "" {
+		return errors.New("Ad requires src, code or placement ID")
+	}
+	if !isPlacementID(s.PlacementID) {
+		return ErrInvalidPlacementID
+	}
+	if _, ok := adTypeSizes[adType(s.Size)]; !ok {
+		return errors.New("Invalid Audience Network ad type " + adType(s.Size))
+	}
+	return nil
+}
+
+// figure returns op-ad figure for ad slot
+func (s AdSlot) figure() Figure {
+	src := s.Src
+	if src == "" && s.Code == "" {
+		src = audienceNetworkAdURL(s.PlacementID, adType(s.Size))
+	}
+	return adFigure(src, s.Size.Width, s.Size.Height, s.Style, s.Code)
+}
+
+// AddAdSlot manually at the end of article content.
+// Error is returned if article has automatic ad placement.
+func (a *Article) AddAdSlot(s AdSlot) error {
+	return a.InsertAdSlot(len(a.Body.Article.Content), s)
+}
+
+// InsertAdSlot manually on position within existing elements, same as InsertFigure.
+// Ads placed manually can't be mixed with automatic ad placement, so error is returned if it is enabled.
+func (a *Article) InsertAdSlot(position int, s AdSlot) error {
+	if a.Options.AutomaticAds {
+		return errAutomaticAds
+	}
+	if err := s.check(); err != nil {
+		return err
+	}
+	a.insert(position, s.figure())
+	return nil
+}
+
+// InsertAdSlotAfterParagraph manually places ad after specified number of paragraphs, same as
+// InsertFigureAfterParagraph. Error is returned if article has automatic ad placement.
+func (a *Article) InsertAdSlotAfterParagraph(paragraphs int, s AdSlot) error {
+	return a.InsertAdSlot(a.paragraphPosition(paragraphs), s)
+}
+
+// InsertAdSlotAfterWords manually places ad after content element in which specified number
+// of words is reached. If content has less words, ad is added at the end of content.
+// Error is returned if article has automatic ad placement.
+func (a *Article) InsertAdSlotAfterWords(words int, s AdSlot) error {
+	return a.InsertAdSlot(a.wordPosition(words), s)
+}
+
+// wordPosition returns content position after element in which n words is reached
+func (a *Article) wordPosition(n int) int {
+	if n <= 0 {
+		return 0
+	}
+	for i, t := range a.Body.Article.Content {
+		if n -= wordCount(t); n <= 0 {
+			return i + 1
+		}
+	}
+	return len(a.Body.Article.Content)
+}
+
+// removeHeaderAd removes default ad from header
+func (a *Article) removeHeaderAd() {
+	h := &a.Body.Article.Header
+	figures := h.Figure[:0]
+	for _, t := range h.Figure {
+		if !isAd(t) {
+			figures = append(figures, t)
+		}
+	}
+	h.Figure = figures
+}
+
+// AdPlacement configures ads placed automatically by Facebook.
+// See https://developers.facebook.com/docs/instant-articles/monetization/ad-placement for more info.
+type AdPlacement struct {
+	Density          string // frequency of ads, one of AdDensity... constants, AdDensityDefault if empty
+	RecirculationAds string // ad placement ID for ads in related articles, optional
+	Ad               AdSlot // default ad which Facebook places in content
+}
+
+// SetAdPlacement enables automatic ad placement with density and default ad in article header.
+// Automatic placement can't be mixed with ads manually placed in content, so error is returned
+// if content already has ads.
+func (a *Article) SetAdPlacement(p AdPlacement) error {
+	if i := a.contentAd(); i >= 0 {
+		return fmt.Errorf("Automatic ad placement can't be mixed with ad placed in content on position %d", i)
+	}
+	switch p.Density {
+	case "", AdDensityDefault, AdDensityMedium, AdDensityLow:
+	default:
+		return errors.New("Invalid ad density " + p.Density)
+	}
+	if err := p.Ad.check(); err != nil {
+		return err
+	}
+
+	a.removeHeaderAd()
+	a.Body.Article.Header.Figure = append(a.Body.Article.Header.Figure, p.Ad.figure())
+
+	a.Options.AutomaticAds = true
+	a.Options.AdDensity = p.Density
+	if p.RecirculationAds != "" {
+		a.Options.RecirculationAds = p.RecirculationAds
+	}
+	return nil
+}
+
+// contentAd returns position of the first ad in content or -1 if there are no ads
+func (a *Article) contentAd() int {
+	for i, t := range a.Body.Article.Content {
+		if isAd(t) {
+			return i
+		}
+	}
+	return -1
+}
+
+// isAd reports if content tag is op-ad figure
+func isAd(t ContentTag) bool {
+	switch f := t.(type) {
+	case Figure:
+		return f.Class == "op-ad"
+	case *Figure:
+		return f.Class == "op-ad"
+	}
+	return false
+}
+
+// AdPolicy describes rules for automatic insertion of ads into article content by InsertAds.
+// Zero value of each rule means no limit.
+type AdPolicy struct {
+	FirstAfterWords      int  // first ad is placed after at least this many words of content
+	MinSpacingWords      int  // minimum number of words between two ads
+	MinSpacingParagraphs int  // minimum number of paragraphs between two ads
+	AvoidFigures         bool // never place ad directly before or after figure
+	MaxAds               int  // maximum number of ads in article, including ads already in content
+}
+
+// Plan returns content positions, as used by InsertFigure, on which ads should be inserted
+// according to policy. Ads already in content are respected. Ads are never placed
+// at the beginning or the end of content or next to another ad.
+func (p AdPolicy) Plan(a *Article) []int {
+	c := a.Body.Article.Content
+	var positions []int
+
+	ads := 0
+	for _, t := range c {
+		if isAd(t) {
+			ads++
+		}
+	}
+
+	words, sinceWords, sinceParagraphs := 0, 0, 0
+	placed := false
+	for i := 1; i < len(c); i++ {
+		prev, next := c[i-1], c[i]
+		if isAd(prev) {
+			sinceWords, sinceParagraphs = 0, 0
+			placed = true
+			continue
+		}
+		n := wordCount(prev)
+		words += n
+		sinceWords += n
+		switch prev.(type) {
+		case P, *P:
+			sinceParagraphs++
+		}
+
+		switch {
+		case p.MaxAds > 0 && ads >= p.MaxAds:
+			return positions
+		case isAd(next):
+		case words < p.FirstAfterWords:
+		case placed && (sinceWords < p.MinSpacingWords || sinceParagraphs < p.MinSpacingParagraphs):
+		case !p.spaced(c[i:]):
+		case p.AvoidFigures && (isFigure(prev) || isFigure(next)):
+		default:
+			positions = append(positions, i)
+			ads++
+			sinceWords, sinceParagraphs = 0, 0
+			placed = true
+		}
+	}
+	return positions
+}
+
+// spaced reports if content before next existing ad in c is long enough to place new ad in front of it
+func (p AdPolicy) spaced(c Content) bool {
+	words, paragraphs := 0, 0
+	for _, t := range c {
+		if isAd(t) {
+			return words >= p.MinSpacingWords && paragraphs >= p.MinSpacingParagraphs
+		}
+		words += wordCount(t)
+		switch t.(type) {
+		case P, *P:
+			paragraphs++
+		}
+	}
+	return true
+}
+
+// InsertAds inserts ads into content on positions computed by policy and returns positions
+// of inserted ads in resulting content. Ads can't be inserted into article with automatic ad placement.
+func (a *Article) InsertAds(p AdPolicy, ad AdSlot) ([]int, error) {
+	if a.Options.AutomaticAds {
+		return nil, errAutomaticAds
+	}
+	if err := ad.check(); err != nil {
+		return nil, err
+	}
+
+	positions := p.Plan(a)
+	for i := len(positions) - 1; i >= 0; i-- {
+		a.insert(positions[i], ad.figure())
+	}
+	// shift positions by ads inserted before them
+	for i := range positions {
+		positions[i] += i
+	}
+	return positions, nil
+}
+
+// isFigure reports if content tag is rendered as <figure>
+func isFigure(t ContentTag) bool {
+	return t.StartElement().Name.Local == "figure"
+}
+
+// wordCount returns number of words in text of content tag
+func wordCount(t ContentTag) int {
+	switch t := t.(type) {
+	case P:
+		return len(strings.Fields(textContent(t.Text)))
+	case *P:
+		return len(strings.Fields(textContent(t.Text)))
+	case Heading:
+		return len(strings.Fields(textContent(t.Text)))
+	case Blockquote:
+		return len(strings.Fields(textContent(t.Text)))
+	case PullQuote:
+		return len(strings.Fields(textContent(t.Text)))
+	case UL:
+		return listWordCount(t.LI)
+	case OL:
+		return listWordCount(t.LI)
+	}
+	return 0
+}
+
+func listWordCount(items []LI) int {
+	n := 0
+	for _, li := range items {
+		n += len(strings.Fields(textContent(li.Text)))
+	}
+	return n
+}
+
+// textContent returns text of HTML without tags
+func textContent(html string) string {
+	var buff strings.Builder
+	d := newHTMLDecoder(strings.NewReader(html))
+	for {
+		t, err := d.Token()
+		if err != nil {
+			return buff.String()
+		}
+		if s, ok := t.(xml.CharData); ok {
+			buff.Write(s)
+			buff.WriteString(" ")
+		}
+	}
+}
@@ -0,0 +1,201 @@
+package instant_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mileusna/facebook-instant-articles"
+)
+
+func TestAdPlacement(t *testing.T) {
+	var a instant.Article
+	a.SetTitle("My article title")
+	a.SetCanonical("http://mysite/url-to-this-article")
+	a.SetPublish(time.Now())
+
+	err := a.SetAdPlacement(instant.AdPlacement{
+		Density:          instant.AdDensityMedium,
+		RecirculationAds: "141956036215488_141956099548815",
+		Ad:               instant.AdSlot{Src: "http://mysite/ad", Size: instant.AdSizeMediumRectangle, Style: "border:0;"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ds := a.Validate(); len(ds) != 0 {
+		t.Errorf("unexpected diagnostics %v", ds)
+	}
+
+	html, err := a.HTML()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{
+		`<meta property="fb:use_automatic_ad_placement" content="enable=true ad_density=medium" />`,
+		`<meta property="fb:op-recirculation-ads" content="placement_id=141956036215488_141956099548815" />`,
+		`<figure class="op-ad"><iframe src="http://mysite/ad" height="250" width="300" style="border:0;"></iframe></figure></header>`,
+	} {
+		if !strings.Contains(string(html), s) {
+			t.Errorf("%s not found in %s", s, html)
+		}
+	}
+
+	if err := a.AddAdSlot(instant.AdSlot{Src: "http://mysite/ad", Size: instant.AdSizeMediumRectangle}); err == nil {
+		t.Error("expected error for ad placed in content of article with automatic ad placement")
+	}
+	a.AddFigure(instant.Figure{Class: "op-ad", IFrame: &instant.IFrame{Src: "http://mysite/ad", Width: "300", Height: "250"}})
+	ds := a.Validate()
+	if len(ds) != 1 || ds[0].Rule != instant.RuleAdMixed || ds[0].Path != "content[0]" {
+		t.Errorf("expected mixed ads diagnostic, got %v", ds)
+	}
+	if err := a.SetAdPlacement(instant.AdPlacement{Ad: instant.AdSlot{Src: "http://mysite/ad", Size: instant.AdSizeMediumRectangle}}); err == nil {
+		t.Error("expected error for ads placed in content")
+	}
+}
+
+func TestInsertAds(t *testing.T) {
+	var a instant.Article
+	a.SetTitle("My article title")
+	a.SetCanonical("http://mysite/url-to-this-article")
+	a.SetContent(`<p>one two three</p><p>four <b>five</b> six</p><figure><img src="http://mysite/img.jpg"/></figure>
+		<p>seven eight nine</p><p>ten eleven twelve</p><p>thirteen fourteen</p><p>fifteen sixteen</p><p>seventeen</p>`)
+
+	policy := instant.AdPolicy{
+		FirstAfterWords:      6,
+		MinSpacingWords:      5,
+		MinSpacingParagraphs: 2,
+		AvoidFigures:         true,
+		MaxAds:               2,
+	}
+	if p := policy.Plan(&a); len(p) != 2 || p[0] != 4 || p[1] != 6 {
+		t.Errorf("unexpected plan %v", p)
+	}
+
+	positions, err := a.InsertAds(policy, instant.AdSlot{Src: "http://mysite/ad", Size: instant.AdSizeMediumRectangle})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(positions) != 2 || positions[0] != 4 || positions[1] != 7 {
+		t.Errorf("unexpected positions %v", positions)
+	}
+	for _, i := range positions {
+		if f, ok := a.Body.Article.Content[i].(instant.Figure); !ok || f.Class != "op-ad" {
+			t.Errorf("expected ad on position %d, got %#v", i, a.Body.Article.Content[i])
+		}
+	}
+
+	if p := policy.Plan(&a); len(p) != 0 {
+		t.Errorf("expected no more ads, got %v", p)
+	}
+
+	// paragraphs added as pointers are counted too
+	var b instant.Article
+	for _, s := range []string{"one two", "three four", "five six", "seven eight", "nine ten"} {
+		b.Body.Article.Content = append(b.Body.Article.Content, &instant.P{Text: s})
+	}
+	if p := (instant.AdPolicy{FirstAfterWords: 2, MinSpacingParagraphs: 2}).Plan(&b); len(p) != 2 || p[0] != 1 || p[1] != 3 {
+		t.Errorf("unexpected plan %v", p)
+	}
+
+	// spacing is kept to ads later in content too
+	var c instant.Article
+	for _, s := range []string{"one", "two", "three", "four", "five"} {
+		c.AddParagraph(s)
+	}
+	c.AddFigure(instant.Figure{Class: "op-ad", IFrame: &instant.IFrame{Src: "http://mysite/ad", Width: "300", Height: "250"}})
+	c.AddParagraph("six")
+	if p := (instant.AdPolicy{MinSpacingParagraphs: 3}).Plan(&c); len(p) != 1 || p[0] != 1 {
+		t.Errorf("unexpected plan %v", p)
+	}
+}
+
+func TestAdSlot(t *testing.T) {
+	var a instant.Article
+	a.SetTitle("My article title")
+	a.SetCanonical("http://mysite/url-to-this-article")
+	a.SetPublish(time.Now())
+	a.AddParagraph("one two three")
+	a.AddParagraph("four five six")
+	a.AddParagraph("seven eight nine")
+
+	for _, err := range []error{
+		a.InsertAdSlotAfterParagraph(1, instant.AdSlot{Src: "http://mysite/ad1", Size: instant.AdSizeMediumRectangle}),
+		a.InsertAdSlotAfterWords(7, instant.AdSlot{Src: "http://mysite/ad2", Size: instant.AdSizeMediumRectangle}),
+		a.AddAdSlot(instant.AdSlot{Code: "<script>ad()</script>", Size: instant.AdSizeMediumRectangle}),
+		a.InsertAd(0, "http://mysite/ad0", 300, 250, "", ""),
+		a.AddAd(2, "http://mysite/ad3", 300, 250, "", ""),
+	} {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := a.AddAdSlot(instant.AdSlot{Size: instant.AdSizeMediumRectangle}); err == nil {
+		t.Error("expected error for empty ad slot")
+	}
+
+	if ds := a.Validate(); len(ds) != 0 {
+		t.Errorf("unexpected diagnostics %v", ds)
+	}
+	html, err := a.HTML()
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := string(html)
+	last := -1
+	for _, o := range []string{"http://mysite/ad0", "one two three", "http://mysite/ad1", "four five six", "http://mysite/ad3", "seven eight nine", "http://mysite/ad2", "ad()"} {
+		i := strings.Index(s, o)
+		if i <= last {
+			t.Errorf("%s not in expected position in %s", o, s)
+		}
+		last = i
+	}
+
+	// automatic ad placement can't be mixed with ads in content
+	if err := a.SetAutomaticAd("http://mysite/ad", 300, 250, "", ""); err == nil {
+		t.Error("expected error for automatic ad in article with ads in content")
+	}
+
+	var b instant.Article
+	b.SetTitle("My article title")
+	b.SetCanonical("http://mysite/url-to-this-article")
+	b.SetPublish(time.Now())
+	b.Options.AdDensity = instant.AdDensityLow
+	slot := instant.AdSlot{PlacementID: "141956036215488_141956099548815", Size: instant.AdSizeBanner}
+	for i := 0; i < 2; i++ {
+		if err := b.SetAdPlacement(instant.AdPlacement{Density: instant.AdDensityLow, Ad: slot}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := b.SetAutomaticAd("http://mysite/ad", 300, 250, "", ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.InsertAd(0, "http://mysite/ad", 300, 250, "", ""); err == nil {
+		t.Error("expected error for ad in content of article with automatic ad placement")
+	}
+	if b.Options.AdDensity != instant.AdDensityLow || len(b.Body.Article.Content) != 0 {
+		t.Errorf("unexpected options %#v or content %#v", b.Options, b.Body.Article.Content)
+	}
+	html, err = b.HTML()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(string(html), `<figure class="op-ad">`); n != 1 {
+		t.Errorf("expected single default ad in %s", html)
+	}
+
+	var c instant.Article
+	c.SetTitle("My article title")
+	c.SetCanonical("http://mysite/url-to-this-article")
+	c.SetPublish(time.Now())
+	if err := c.SetAdPlacement(instant.AdPlacement{Ad: slot}); err != nil {
+		t.Fatal(err)
+	}
+	html, err = c.HTML()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ad := `<figure class="op-ad"><iframe src="https://www.facebook.com/adnw_request?placement=141956036215488_141956099548815&amp;adtype=banner320x50" height="50" width="320"></iframe></figure>`
+	if !strings.Contains(string(html), ad) {
+		t.Errorf("%s not found in %s", ad, html)
+	}
+}
@@ -1,19 +1,11 @@
 package instant
 
 import (
-	"bytes"
 	"encoding/xml"
-	"errors"
-	"regexp"
 	"strconv"
-	"strings"
 	"time"
 )
 
-var (
-	matchContent = regexp.MustCompile("((?:<p>|<figure[^>]*>).*?(?:</p>|</figure>))")
-)
-
 // Article struct
 type Article struct {
 	Prefix string `xml:"prefix,attr"`
@@ -21,6 +13,8 @@ type Article struct {
 
 	Head head `xml:"head"`
 	Body body `xml:"body"`
+
+	Options ArticleOptions `xml:"-"`
 }
 
 type head struct {
@@ -34,7 +28,7 @@ type body struct {
 
 type article struct {
 	Header  header  `xml:"header"`
-	Content Content `xml:" ,"`
+	Content Content `xml:"content"`
 	Footer  footer  `xml:"footer"`
 }
 
@@ -53,27 +47,33 @@ type P struct {
 
 // Figure HTML <figure>
 type Figure struct {
-	Img        *Img    `xml:"img,omitempty"`
-	IFrame     *IFrame `xml:"iframe,omitempty"`
-	Video      *Video  `xml:"video,omitempty"`
-	Figcaption string  `xml:"figcaption,omitempty"`
-	Class      string  `xml:"class,attr,omitempty"`
+	Img        *Img     `xml:"img,omitempty"`
+	IFrame     *IFrame  `xml:"iframe,omitempty"`
+	Video      *Video   `xml:"video,omitempty"`
+	Audio      *Audio   `xml:"audio,omitempty"`
+	Geotag     *Geotag  `xml:"script,omitempty"`
+	Figcaption *Caption `xml:"figcaption,omitempty"`
+	Class      string   `xml:"class,attr,omitempty"`
+	Mode       string   `xml:"data-mode,attr,omitempty"`
+	Feedback   string   `xml:"data-feedback,attr,omitempty"`
 }
 
 // Header represents instant article header
 type header struct {
-	H1      string    `xml:"h1"`
-	Time    []Time    `xml:"time"`
-	H2      string    `xml:"h2,omitempty"`
-	H3      *h3       `xml:"h3,omitempty"`
-	Address []address `xml:"address,omitempty"`
-	Figure  []*Figure `xml:"figure,omitempty"`
+	H1       string    `xml:"h1"`
+	Time     []Time    `xml:"time"`
+	H2       string    `xml:"h2,omitempty"`
+	H3       *h3       `xml:"h3,omitempty"`
+	Address  []address `xml:"address,omitempty"`
+	Figure   Content   `xml:"figure,omitempty"`
+	Sponsors *Sponsors `xml:"ul,omitempty"`
 }
 
 // Footer represents instant article footer
 type footer struct {
-	Aside string `xml:"aside,omitempty"`
-	Small string `xml:"small,omitempty"`
+	Aside   string           `xml:"aside,omitempty"`
+	Small   string           `xml:"small,omitempty"`
+	Related *RelatedArticles `xml:"ul,omitempty"`
 }
 
 // link for canonical link tag
@@ -84,11 +84,15 @@ type link struct {
 
 // Video for article
 type Video struct {
-	Source source `xml:"source"`
+	Source          []Source `xml:"source"`
+	Poster          string   `xml:"-"`
+	Loop            bool     `xml:"-"`
+	Controls        bool     `xml:"-"`
+	DisableAutoplay bool     `xml:"-"`
 }
 
-// source video struct
-type source struct {
+// Source video struct
+type Source struct {
 	Src  string `xml:"src,attr"`
 	Type string `xml:"type,attr"`
 }
@@ -100,6 +104,7 @@ type IFrame struct {
 	Width  string `xml:"width,attr,omitempty"`
 	Style  string `xml:"style,attr,omitempty"`
 	Hidden string `xml:"hidden,attr,omitempty"`
+	Class  string `xml:"class,attr,omitempty"`
 	Text   string `xml:",innerxml"`
 }
 
@@ -138,17 +143,22 @@ type Time struct {
 
 // Img struct for images
 type Img struct {
-	Src string `xml:"src,attr"`
+	Src    string `xml:"src,attr"`
+	Width  string `xml:"width,attr,omitempty"`
+	Height string `xml:"height,attr,omitempty"`
 }
 
 // MarshalXML for xml.Marshaler interface, marshal Article struct to Facebook Instant Article format.
 func (a Article) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
 	// check required fields
-	if a.Body.Article.Header.H1 == "" {
-		return errors.New("Article title <h1> is required")
+	if ds := a.required(); ds != nil {
+		return ds[0]
 	}
-	if a.Head.Link.Href == "" {
-		return errors.New("Canonical link is required")
+	// manual and automatic ads can't be mixed
+	var v validator
+	v.ads(&a)
+	if v.diagnostics != nil {
+		return v.diagnostics[0]
 	}
 
 	html := struct {
@@ -163,13 +173,19 @@ func (a Article) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
 	}{}
 
 	html.Body.Article = a.Body.Article
+	html.Body.Article.Header.Figure = a.Options.feedback(a.Body.Article.Header.Figure)
+	html.Body.Article.Content = a.Options.feedback(a.Body.Article.Content)
 	if a.Lang != "" {
 		html.Lang = a.Lang
 	} else {
 		html.Lang = "en"
 	}
 	html.Prefix = "op: http://media.facebook.com/op#"
-	html.Head.S = a.headString()
+	head, err := a.headString()
+	if err != nil {
+		return err
+	}
+	html.Head.S = head
 
 	start.Name.Local = "html" // rename root element from Article to html
 	e.EncodeToken(xml.Directive("doctype html"))
@@ -197,55 +213,6 @@ func (c Content) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
 	return nil
 }
 
-// headString returns instant article head section with self-closing <meta/> and <link/> tags.
-// xml.Marshal by default doesn't produce self-closing tags
-func (a *Article) headString() string {
-	var buff bytes.Buffer
-
-	// link element
-	buff.WriteString("\n<link href=\"")
-	buff.WriteString(a.Head.Link.Href)
-	buff.WriteString("\" rel=\"")
-	buff.WriteString(a.Head.Link.Rel)
-	buff.WriteString("\" />")
-
-	// meta elements
-	var charset, markupVersion bool
-	for _, m := range a.Head.Meta {
-		buff.WriteString("\n<meta ")
-		if m.Charset != "" {
-			buff.WriteString("charset=\"")
-			buff.WriteString(m.Charset)
-			buff.WriteString("\" ")
-			charset = true
-		}
-		if m.Property != "" {
-			buff.WriteString("property=\"")
-			buff.WriteString(m.Property)
-			buff.WriteString("\" ")
-			if m.Property == "op:markup_version" {
-				markupVersion = true
-			}
-		}
-		if m.Content != "" {
-			buff.WriteString("content=\"")
-			buff.WriteString(m.Content)
-			buff.WriteString("\" ")
-		}
-		buff.WriteString("/>")
-	}
-
-	// write default meta elements if not set in meta tags
-	if !charset {
-		buff.WriteString("\n<meta charset=\"utf-8\" />")
-	}
-	if !markupVersion {
-		buff.WriteString("\n<meta property=\"op:markup_version\" content=\"v1.0\" />")
-	}
-
-	return buff.String()
-}
-
 // SetTitle sets article title.
 // Setting article title is mandatory.
 func (a *Article) SetTitle(title string) {
@@ -280,33 +247,21 @@ func (a *Article) SetKick(kick string) {
 // SetStyle set user defined style for this article.
 // See https://developers.facebook.com/docs/instant-articles/guides/design#style for more info.
 func (a *Article) SetStyle(style string) {
-	a.Head.Meta = append(a.Head.Meta, Meta{
-		Property: "fb:article_style",
-		Content:  style,
-	})
+	a.Options.Style = style
 }
 
 // SetContent of instant article.
-// All html should be in <p> ... </p> elements. If no <p> elements found, entire HTML param will be added as one paragraph.
+// HTML is parsed and every recognized block element is added to article content. Bare text and inline
+// elements between blocks are added as paragraphs. Elements which can't be represented in instant article
+// are returned as dropped. If HTML can't be parsed, content parsed before error is added
+// and the rest of HTML is returned as dropped with error as reason.
 // Images and videos can be added later using InsertFigure() but they can also be contained in HTML param if formated properly.
 // See https://developers.facebook.com/docs/instant-articles/reference for more info.
-func (a *Article) SetContent(html string) {
-	if matches := matchContent.FindAllString(html, -1); matches != nil {
-		for _, m := range matches {
-			switch {
-			case strings.HasPrefix(m, "<p"):
-				m = strings.TrimPrefix(m, "<p>")
-				m = strings.TrimSuffix(m, "</p>")
-				a.AddParagraph(m)
-			case strings.HasPrefix(m, "<figure"):
-				f := Figure{}
-				xml.Unmarshal([]byte(m), &f)
-				a.AddFigure(f)
-			}
-		}
-	} else {
-		a.AddParagraph(html)
-	}
+func (a *Article) SetContent(html string) []Dropped {
+	// error is reported in dropped
+	c, dropped, _ := ParseContent(html)
+	a.Body.Article.Content = append(a.Body.Article.Content, c...)
+	return dropped
 }
 
 // AddParagraph to Instant Article.
@@ -346,11 +301,14 @@ func (a *Article) SetModified(date time.Time) {
 // SetCoverImage of instant article.
 // Caption can be empty string.
 func (a *Article) SetCoverImage(url, caption string) {
+	a.SetCoverImageWithOptions(url, caption, ImageOptions{})
+}
+
+// SetCoverImageWithOptions sets cover image with presentation mode, feedback and size options.
+// Caption can be empty string.
+func (a *Article) SetCoverImageWithOptions(url, caption string, o ImageOptions) {
 	if url != "" {
-		a.Body.Article.Header.Figure = append(a.Body.Article.Header.Figure, &Figure{
-			Img:        &Img{Src: url},
-			Figcaption: caption,
-		})
+		a.Body.Article.Header.Figure = append(a.Body.Article.Header.Figure, NewImageFigure(url, caption, o))
 	}
 }
 
@@ -358,16 +316,14 @@ func (a *Article) SetCoverImage(url, caption string) {
 // videoType in format video/mp4 See the list of supported formats here https://www.facebook.com/help/218673814818907
 // Caption can be empty string.
 func (a *Article) SetCoverVideo(url, videoType, caption string) {
+	a.SetCoverVideoWithOptions(url, videoType, caption, VideoOptions{})
+}
+
+// SetCoverVideoWithOptions sets cover video with playback, presentation and additional sources options.
+// Caption can be empty string.
+func (a *Article) SetCoverVideoWithOptions(url, videoType, caption string, o VideoOptions) {
 	if url != "" {
-		a.Body.Article.Header.Figure = append(a.Body.Article.Header.Figure, &Figure{
-			Video: &Video{
-				Source: source{
-					Src:  url,
-					Type: videoType,
-				},
-			},
-			Figcaption: caption,
-		})
+		a.Body.Article.Header.Figure = append(a.Body.Article.Header.Figure, NewVideoFigure(url, videoType, caption, o))
 	}
 }
 
@@ -402,32 +358,26 @@ func (a *Article) SetTrackerURL(url string) {
 	a.Body.Article.Content = append(a.Body.Article.Content, f)
 }
 
-// switchAutomaticAd positioning by Facebook and choose to manually position ads in article content
-func (a *Article) switchAutomaticAd(on bool) {
-	a.Head.Meta = append(a.Head.Meta, Meta{
-		Property: "fb:use_automatic_ad_placement",
-		Content:  strconv.FormatBool(on),
+// SetAutomaticAd in header that Facebook will place automatically in article, same as SetAdPlacement
+// with current ad density. Existing default ad in header is replaced.
+func (a *Article) SetAutomaticAd(src string, width, height int, style, code string) error {
+	return a.SetAdPlacement(AdPlacement{
+		Density: a.Options.AdDensity,
+		Ad:      AdSlot{Src: src, Size: AdSize{width, height}, Style: style, Code: code},
 	})
 }
 
-// SetAutomaticAd in header that Facebook will place automatically in article
-func (a *Article) SetAutomaticAd(src string, width, height int, style, code string) {
-	f := adFigure(src, width, height, style, code)
-	a.Body.Article.Header.Figure = append(a.Body.Article.Header.Figure, &f)
-	a.switchAutomaticAd(true)
+// InsertAd manually on position within existing elements, same as InsertFigure.
+// Error is returned if article has automatic ad placement.
+func (a *Article) InsertAd(position int, src string, width, height int, style, code string) error {
+	return a.InsertAdSlot(position, AdSlot{Src: src, Size: AdSize{width, height}, Style: style, Code: code})
 }
 
-// InsertAd manually on position between paragraphs.
-func (a *Article) InsertAd(position int, src string, width, height int, style, code string) {
-	a.InsertFigure(position, adFigure(src, width, height, style, code))
-	a.switchAutomaticAd(false)
-}
-
-// AddAd manually in article content.
-func (a *Article) AddAd(position int, src string, width, height int, style, code string) {
-	f := adFigure(src, width, height, style, code)
-	a.switchAutomaticAd(false)
-	a.AddFigure(f)
+// AddAd manually in article content after specified number of paragraphs, same as InsertAdSlotAfterParagraph.
+// If content has less paragraphs, ad is added at the end of content.
+// Error is returned if article has automatic ad placement.
+func (a *Article) AddAd(position int, src string, width, height int, style, code string) error {
+	return a.InsertAdSlotAfterParagraph(position, AdSlot{Src: src, Size: AdSize{width, height}, Style: style, Code: code})
 }
 
 // AddFigure to article content
@@ -437,11 +387,42 @@ func (a *Article) AddFigure(f Figure) {
 
 // InsertFigure in content on specified position within existing elements (paragraphs)
 func (a *Article) InsertFigure(position int, f Figure) {
-	//e := element{Figure: f}
+	a.insert(position, f)
+}
+
+// InsertFigureAfterParagraph in content after specified number of paragraphs.
+// Only paragraphs are counted, other elements like figures or related articles are skipped.
+// If content has less paragraphs, figure is added at the end of content.
+func (a *Article) InsertFigureAfterParagraph(paragraphs int, f Figure) {
+	a.insert(a.paragraphPosition(paragraphs), f)
+}
+
+// paragraphPosition returns content position after n paragraphs
+func (a *Article) paragraphPosition(n int) int {
+	if n <= 0 {
+		return 0
+	}
+	for i, t := range a.Body.Article.Content {
+		switch t.(type) {
+		case P, *P:
+			if n--; n == 0 {
+				return i + 1
+			}
+		}
+	}
+	return len(a.Body.Article.Content)
+}
+
+// insert content tag on specified position within existing elements.
+// Every content element, not only paragraphs, counts as one position.
+func (a *Article) insert(position int, t ContentTag) {
+	if position < 0 {
+		position = 0
+	}
 	if position >= len(a.Body.Article.Content) {
 		position = len(a.Body.Article.Content)
 	}
-	a.Body.Article.Content = append(a.Body.Article.Content[:position], append(Content{f}, a.Body.Article.Content[position:]...)...)
+	a.Body.Article.Content = append(a.Body.Article.Content[:position], append(Content{t}, a.Body.Article.Content[position:]...)...)
 }
 
 // adFigre create figure with ad
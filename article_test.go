@@ -37,7 +37,9 @@ func TestArticle(t *testing.T) {
 
 	a.SetContent("<p>Plain text</p><figure><iframe>a = 0;</iframe></figure><p>Plain <b>text</b></p><p>Plain text 22</p>")
 	a.AddParagraph("End <strong>The</strong>")
-	a.SetAutomaticAd("https://www.facebook.com/adnw_request?placement=141956036215488_141956099548815&adtype=banner320x50", 320, 50, "border:0; margin:0;", "")
+	if err := a.SetAutomaticAd("https://www.facebook.com/adnw_request?placement=141956036215488_141956099548815&adtype=banner320x50", 320, 50, "border:0; margin:0;", ""); err != nil {
+		t.Fatal(err)
+	}
 	//a.InsertAd(7, "https://www.facebook.com/adnw_request?placement=141956036215488_141956099548815&adtype=banner320x50", 320, 50, "border:0; margin:0;", "")
 
 	_, err := xml.MarshalIndent(a, "", "    ")
@@ -0,0 +1,62 @@
+package instant
+
+import (
+	"errors"
+	"net/url"
+	"regexp"
+	"strconv"
+)
+
+// Audience Network ad types
+const (
+	AdTypeBanner          = "banner320x50"
+	AdTypeMediumRectangle = "banner300x250"
+)
+
+// audienceNetworkURL is base url of Audience Network ad iframe
+const audienceNetworkURL = "https://www.facebook.com/adnw_request"
+
+// adTypeSizes maps Audience Network ad types to iframe sizes
+var adTypeSizes = map[string]AdSize{
+	AdTypeBanner:          AdSizeBanner,
+	AdTypeMediumRectangle: AdSizeMediumRectangle,
+}
+
+// ErrInvalidPlacementID is returned when Audience Network placement ID is not in format 123_456
+var ErrInvalidPlacementID = errors.New("Invalid Audience Network placement ID")
+
+var matchPlacementID = regexp.MustCompile(`^[0-9]+_[0-9]+$`)
+
+// NewAudienceNetworkAd creates ad slot for Facebook Audience Network ad.
+// placementID is in format 141956036215488_141956099548815 and adType is one of AdType... constants.
+// Returned slot can be used with SetAdPlacement, AddAdSlot or InsertAdSlot.
+func NewAudienceNetworkAd(placementID, adType string) (AdSlot, error) {
+	if !isPlacementID(placementID) {
+		return AdSlot{}, ErrInvalidPlacementID
+	}
+	size, ok := adTypeSizes[adType]
+	if !ok {
+		return AdSlot{}, errors.New("Invalid Audience Network ad type " + adType)
+	}
+	return AdSlot{
+		PlacementID: placementID,
+		Size:        size,
+		Style:       "border:0; margin:0;",
+		Src:         audienceNetworkAdURL(placementID, adType),
+	}, nil
+}
+
+// audienceNetworkAdURL returns url of Audience Network ad iframe
+func audienceNetworkAdURL(placementID, adType string) string {
+	return audienceNetworkURL + "?placement=" + url.QueryEscape(placementID) + "&adtype=" + url.QueryEscape(adType)
+}
+
+// adType returns Audience Network ad type for ad size
+func adType(size AdSize) string {
+	return "banner" + strconv.Itoa(size.Width) + "x" + strconv.Itoa(size.Height)
+}
+
+// isPlacementID checks Audience Network placement ID format
+func isPlacementID(id string) bool {
+	return matchPlacementID.MatchString(id)
+}
@@ -0,0 +1,90 @@
+package instant_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mileusna/facebook-instant-articles"
+)
+
+func TestAudienceNetworkAd(t *testing.T) {
+	ad, err := instant.NewAudienceNetworkAd("141956036215488_141956099548815", instant.AdTypeMediumRectangle)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ad.Size != instant.AdSizeMediumRectangle {
+		t.Errorf("expected size %v, got %v", instant.AdSizeMediumRectangle, ad.Size)
+	}
+	if url := "https://www.facebook.com/adnw_request?placement=141956036215488_141956099548815&adtype=banner300x250"; ad.Src != url {
+		t.Errorf("expected src %s, got %s", url, ad.Src)
+	}
+
+	for _, c := range []struct{ id, adType string }{
+		{"141956036215488", instant.AdTypeBanner},
+		{"141956036215488_", instant.AdTypeBanner},
+		{"abc_141956099548815", instant.AdTypeBanner},
+		{"141956036215488_141956099548815", "banner728x90"},
+	} {
+		if _, err := instant.NewAudienceNetworkAd(c.id, c.adType); err == nil {
+			t.Errorf("expected error for %s %s", c.id, c.adType)
+		}
+	}
+	if _, err := instant.NewAudienceNetworkAd("1234", instant.AdTypeBanner); err != instant.ErrInvalidPlacementID {
+		t.Errorf("expected ErrInvalidPlacementID, got %v", err)
+	}
+
+	var a instant.Article
+	a.SetTitle("My article title")
+	a.SetCanonical("http://mysite/url-to-this-article")
+	a.SetPublish(time.Now())
+	if err := a.SetAdPlacement(instant.AdPlacement{Ad: ad}); err != nil {
+		t.Fatal(err)
+	}
+	if ds := a.Validate(); len(ds) != 0 {
+		t.Errorf("unexpected diagnostics %v", ds)
+	}
+	html, err := a.HTML()
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := `<figure class="op-ad"><iframe src="https://www.facebook.com/adnw_request?placement=141956036215488_141956099548815&amp;adtype=banner300x250" height="250" width="300" style="border:0; margin:0;"></iframe></figure>`
+	if !strings.Contains(string(html), f) {
+		t.Errorf("%s not found in %s", f, html)
+	}
+
+	// ad slot with placement ID only is checked same as NewAudienceNetworkAd
+	for _, s := range []instant.AdSlot{
+		{PlacementID: "141956036215488_141956099548815", Size: instant.AdSizeLargeBanner},
+		{PlacementID: "141956036215488", Size: instant.AdSizeBanner},
+	} {
+		var b instant.Article
+		if err := b.AddAdSlot(s); err == nil {
+			t.Errorf("expected error for %#v", s)
+		}
+	}
+}
+
+func TestValidateAudienceNetworkAd(t *testing.T) {
+	var a instant.Article
+	a.SetTitle("My article title")
+	a.SetCanonical("http://mysite/url-to-this-article")
+	a.SetPublish(time.Now())
+	a.AddParagraph("Plain text")
+
+	ad, _ := instant.NewAudienceNetworkAd("141956036215488_141956099548815", instant.AdTypeBanner)
+	a.AddAdSlot(ad)
+	a.AddAd(1, "https://www.facebook.com/adnw_request?placement=12345&adtype=banner320x50", 320, 50, "", "")
+	a.AddAd(1, "https://www.facebook.com/adnw_request?placement=1_2&adtype=banner320x50", 300, 250, "", "")
+
+	ds := a.Validate()
+	if len(ds) != 2 {
+		t.Fatalf("expected 2 diagnostics, got %v", ds)
+	}
+	if ds[0].Rule != instant.RuleAdSize || ds[0].Severity != instant.SeverityWarning {
+		t.Errorf("expected ad size warning, got %v", ds[0])
+	}
+	if ds[1].Rule != instant.RuleAdPlacement || !strings.Contains(ds[1].Message, "12345") {
+		t.Errorf("expected placement error, got %v", ds[1])
+	}
+}
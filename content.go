@@ -0,0 +1,222 @@
+package instant
+
+import (
+	"encoding/xml"
+	"strings"
+)
+
+// MaxRelatedArticles is maximum number of related articles in footer
+const MaxRelatedArticles = 3
+
+// UL HTML unordered list <ul>
+type UL struct {
+	LI []LI `xml:"li"`
+}
+
+// OL HTML ordered list <ol>
+type OL struct {
+	LI []LI `xml:"li"`
+}
+
+// LI HTML list item <li>
+type LI struct {
+	Text string `xml:",innerxml"`
+}
+
+// Heading HTML section heading <h1> or <h2> within article content
+type Heading struct {
+	Level int    `xml:"-"`
+	Text  string `xml:",innerxml"`
+}
+
+// Blockquote HTML <blockquote>
+type Blockquote struct {
+	Text string `xml:",innerxml"`
+}
+
+// PullQuote is <aside> element with optional <cite> attribution
+type PullQuote struct {
+	Text string `xml:",innerxml"`
+	Cite string `xml:"cite,omitempty"`
+}
+
+// StartElement for ContentElement interface
+func (l UL) StartElement() xml.StartElement {
+	return xml.StartElement{Name: xml.Name{Local: "ul"}}
+}
+
+// StartElement for ContentElement interface
+func (l OL) StartElement() xml.StartElement {
+	return xml.StartElement{Name: xml.Name{Local: "ol"}}
+}
+
+// StartElement for ContentElement interface.
+// Instant articles support only two heading levels, level 2 and below are rendered as <h2>.
+func (h Heading) StartElement() xml.StartElement {
+	if h.Level <= 1 {
+		return xml.StartElement{Name: xml.Name{Local: "h1"}}
+	}
+	return xml.StartElement{Name: xml.Name{Local: "h2"}}
+}
+
+// StartElement for ContentElement interface
+func (q Blockquote) StartElement() xml.StartElement {
+	return xml.StartElement{Name: xml.Name{Local: "blockquote"}}
+}
+
+// StartElement for ContentElement interface
+func (q PullQuote) StartElement() xml.StartElement {
+	return xml.StartElement{Name: xml.Name{Local: "aside"}}
+}
+
+// AddBlockquote to instant article content. Quote can contain inline html.
+func (a *Article) AddBlockquote(html string) {
+	a.Body.Article.Content = append(a.Body.Article.Content, Blockquote{Text: html})
+}
+
+// AddPullQuote to instant article content. Quote can contain inline html.
+// Cite is quote attribution and can be empty string.
+func (a *Article) AddPullQuote(html, cite string) {
+	a.Body.Article.Content = append(a.Body.Article.Content, PullQuote{Text: html, Cite: cite})
+}
+
+// AddHeading to instant article content.
+// Level 1 is rendered as <h1>, all other levels as <h2>.
+func (a *Article) AddHeading(level int, text string) {
+	a.Body.Article.Content = append(a.Body.Article.Content, Heading{Level: headingLevel(level), Text: text})
+}
+
+// headingLevel downgrades HTML heading level to levels supported by instant articles
+func headingLevel(level int) int {
+	if level <= 1 {
+		return 1
+	}
+	return 2
+}
+
+// AddUnorderedList to instant article. Items can contain inline html.
+func (a *Article) AddUnorderedList(items ...string) {
+	a.Body.Article.Content = append(a.Body.Article.Content, UL{LI: listItems(items)})
+}
+
+// AddOrderedList to instant article. Items can contain inline html.
+func (a *Article) AddOrderedList(items ...string) {
+	a.Body.Article.Content = append(a.Body.Article.Content, OL{LI: listItems(items)})
+}
+
+// listItems creates list items from strings
+func listItems(items []string) []LI {
+	li := make([]LI, len(items))
+	for i, s := range items {
+		li[i] = LI{Text: s}
+	}
+	return li
+}
+
+// RelatedArticles is <ul class="op-related-articles"> list of links to related articles.
+// See https://developers.facebook.com/docs/instant-articles/reference/related-articles for more info.
+type RelatedArticles struct {
+	Title    string // optional list title, e.g. "More stories"
+	Articles []RelatedArticle
+}
+
+// RelatedArticle is single link in related articles list
+type RelatedArticle struct {
+	URL       string
+	Sponsored bool
+}
+
+// AddRelatedArticle adds link to related article in article footer.
+func (a *Article) AddRelatedArticle(url string, sponsored bool) {
+	f := &a.Body.Article.Footer
+	if f.Related == nil {
+		f.Related = &RelatedArticles{}
+	}
+	f.Related.Articles = append(f.Related.Articles, RelatedArticle{URL: url, Sponsored: sponsored})
+}
+
+// SetRelatedArticlesTitle sets title of related articles list in article footer.
+func (a *Article) SetRelatedArticlesTitle(title string) {
+	f := &a.Body.Article.Footer
+	if f.Related == nil {
+		f.Related = &RelatedArticles{}
+	}
+	f.Related.Title = title
+}
+
+// StartElement for ContentElement interface
+func (r RelatedArticles) StartElement() xml.StartElement {
+	return xml.StartElement{Name: xml.Name{Local: "ul"}}
+}
+
+// AddRelatedArticles adds related articles list to article content.
+func (a *Article) AddRelatedArticles(r RelatedArticles) {
+	a.Body.Article.Content = append(a.Body.Article.Content, r)
+}
+
+// InsertRelatedArticles in content on specified position within existing elements,
+// same as InsertFigure.
+func (a *Article) InsertRelatedArticles(position int, r RelatedArticles) {
+	a.insert(position, r)
+}
+
+// InsertRelatedArticlesAfterParagraph in content after specified number of paragraphs,
+// same as InsertFigureAfterParagraph.
+func (a *Article) InsertRelatedArticlesAfterParagraph(paragraphs int, r RelatedArticles) {
+	a.insert(a.paragraphPosition(paragraphs), r)
+}
+
+// MarshalXML for xml.Marshaler interface
+func (r RelatedArticles) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	start.Name.Local = "ul"
+	start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "class"}, Value: "op-related-articles"})
+	if r.Title != "" {
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "title"}, Value: r.Title})
+	}
+
+	type a struct {
+		Href string `xml:"href,attr"`
+	}
+	type li struct {
+		Sponsored string `xml:"data-sponsored,attr,omitempty"`
+		A         a      `xml:"a"`
+	}
+	v := struct {
+		LI []li `xml:"li"`
+	}{}
+	for _, ra := range r.Articles {
+		item := li{A: a{Href: ra.URL}}
+		if ra.Sponsored {
+			item.Sponsored = "true"
+		}
+		v.LI = append(v.LI, item)
+	}
+	return e.EncodeElement(v, start)
+}
+
+// UnmarshalXML for xml.Unmarshaler interface
+func (r *RelatedArticles) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	for _, attr := range start.Attr {
+		if attr.Name.Local == "title" {
+			r.Title = attr.Value
+		}
+	}
+	v := struct {
+		LI []struct {
+			Sponsored *string `xml:"data-sponsored,attr"`
+			A         struct {
+				Href string `xml:"href,attr"`
+			} `xml:"a"`
+		} `xml:"li"`
+	}{}
+	if err := d.DecodeElement(&v, &start); err != nil {
+		return err
+	}
+	for _, li := range v.LI {
+		r.Articles = append(r.Articles, RelatedArticle{
+			URL:       li.A.Href,
+			Sponsored: li.Sponsored != nil && !strings.EqualFold(*li.Sponsored, "false"),
+		})
+	}
+	return nil
+}
@@ -0,0 +1,149 @@
+package instant_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mileusna/facebook-instant-articles"
+)
+
+func TestList(t *testing.T) {
+	var a instant.Article
+	a.SetTitle("My article title")
+	a.SetCanonical("http://mysite/url-to-this-article")
+	a.AddUnorderedList("One", "<b>Two</b>")
+	dropped := a.SetContent("<ol><li>First</li> <li></li><p>Wrong</p><li>Second</li></ol>")
+
+	html, err := a.HTML()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(html), "<ul><li>One</li><li><b>Two</b></li></ul><ol><li>First</li><li>Second</li></ol>") {
+		t.Errorf("lists not found in %s", html)
+	}
+	if len(dropped) != 1 || dropped[0].Tag != "p" {
+		t.Errorf("unexpected dropped elements %#v", dropped)
+	}
+}
+
+func TestHeading(t *testing.T) {
+	var a instant.Article
+	a.SetTitle("My article title")
+	a.SetCanonical("http://mysite/url-to-this-article")
+	a.AddParagraph("Intro")
+	a.AddHeading(1, "Section")
+	a.SetContent("<h3>Subsection</h3><p>Text</p>")
+
+	html, err := a.HTML()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(html), "<p>Intro</p><h1>Section</h1><h2>Subsection</h2><p>Text</p>") {
+		t.Errorf("headings not found in %s", html)
+	}
+}
+
+func TestQuote(t *testing.T) {
+	var a instant.Article
+	a.SetTitle("My article title")
+	a.SetCanonical("http://mysite/url-to-this-article")
+	a.AddBlockquote("To be <i>or</i> not to be")
+	a.SetContent("<aside>Quote <b>me</b><cite>Tom &amp; Jerry</cite></aside>")
+
+	html, err := a.HTML()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(html), "<blockquote>To be <i>or</i> not to be</blockquote><aside>Quote <b>me</b><cite>Tom &amp; Jerry</cite></aside>") {
+		t.Errorf("quotes not found in %s", html)
+	}
+}
+
+func TestRelatedArticles(t *testing.T) {
+	var a instant.Article
+	a.SetTitle("My article title")
+	a.SetCanonical("http://mysite/url-to-this-article")
+	a.SetFooter("", "©MyComp 2016")
+	a.SetRelatedArticlesTitle("More stories")
+	a.AddRelatedArticle("http://mysite/other-article", false)
+	a.AddRelatedArticle("http://mysite/sponsored-article", true)
+
+	html, err := a.HTML()
+	if err != nil {
+		t.Fatal(err)
+	}
+	related := `<footer><small>©MyComp 2016</small><ul class="op-related-articles" title="More stories"><li><a href="http://mysite/other-article"></a></li><li data-sponsored="true"><a href="http://mysite/sponsored-article"></a></li></ul></footer>`
+	if !strings.Contains(string(html), related) {
+		t.Fatalf("related articles not found in %s", html)
+	}
+
+	b, err := instant.ParseArticle(strings.NewReader(string(html)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r := b.Body.Article.Footer.Related; r == nil || r.Title != "More stories" || len(r.Articles) != 2 || !r.Articles[1].Sponsored {
+		t.Errorf("unexpected related articles %#v", r)
+	}
+
+	a.AddRelatedArticle("/relative-url", false)
+	a.AddRelatedArticle("http://mysite/fourth-article", false)
+	rules := map[string]bool{}
+	for _, d := range a.Validate() {
+		rules[d.Rule] = true
+	}
+	if !rules[instant.RuleRelatedCount] || !rules[instant.RuleRelatedURL] {
+		t.Errorf("expected related articles diagnostics, got %v", rules)
+	}
+}
+
+func TestRelatedArticlesContent(t *testing.T) {
+	var a instant.Article
+	a.SetTitle("My article title")
+	a.SetCanonical("http://mysite/url-to-this-article")
+	a.SetContent("<p>Paragraph 1</p><p>Paragraph 2</p>")
+	a.InsertRelatedArticles(1, instant.RelatedArticles{
+		Title:    "Read also",
+		Articles: []instant.RelatedArticle{{URL: "http://mysite/other-article"}},
+	})
+	a.InsertFigure(2, instant.NewImageFigure("http://mysite/img.jpg", "", instant.ImageOptions{}))
+
+	html, err := a.HTML()
+	if err != nil {
+		t.Fatal(err)
+	}
+	content := `<p>Paragraph 1</p><ul class="op-related-articles" title="Read also"><li><a href="http://mysite/other-article"></a></li></ul><figure><img src="http://mysite/img.jpg"></img></figure><p>Paragraph 2</p>`
+	if !strings.Contains(string(html), content) {
+		t.Fatalf("related articles not found in %s", html)
+	}
+
+	b, err := instant.ParseArticle(strings.NewReader(string(html)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r, ok := b.Body.Article.Content[1].(instant.RelatedArticles); !ok || len(r.Articles) != 1 {
+		t.Errorf("unexpected related articles %#v", b.Body.Article.Content[1])
+	}
+}
+
+func TestInsertAfterParagraph(t *testing.T) {
+	var a instant.Article
+	a.SetTitle("My article title")
+	a.SetCanonical("http://mysite/url-to-this-article")
+	a.SetContent("<p>Paragraph 1</p><p>Paragraph 2</p><p>Paragraph 3</p>")
+	a.InsertRelatedArticlesAfterParagraph(1, instant.RelatedArticles{
+		Articles: []instant.RelatedArticle{{URL: "http://mysite/other-article"}},
+	})
+	// related articles are not counted as paragraph
+	a.InsertFigureAfterParagraph(2, instant.NewImageFigure("http://mysite/img.jpg", "", instant.ImageOptions{}))
+	a.InsertFigureAfterParagraph(10, instant.NewImageFigure("http://mysite/last.jpg", "", instant.ImageOptions{}))
+
+	html, err := a.HTML()
+	if err != nil {
+		t.Fatal(err)
+	}
+	content := `<p>Paragraph 1</p><ul class="op-related-articles"><li><a href="http://mysite/other-article"></a></li></ul><p>Paragraph 2</p>` +
+		`<figure><img src="http://mysite/img.jpg"></img></figure><p>Paragraph 3</p><figure><img src="http://mysite/last.jpg"></img></figure>`
+	if !strings.Contains(string(html), content) {
+		t.Fatalf("%s not found in %s", content, html)
+	}
+}
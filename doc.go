@@ -42,6 +42,12 @@ Example:
         // html contains Facebook instant article html as []byte
     }
 
+Article.Validate() checks article against instant articles specification and returns
+list of diagnostics, so problems can be reported to editors before article is published.
+
+Already published instant article html can be read back into instant.Article
+using instant.ParseArticle(), for example to compare or patch it.
+
 Struct instant.Feed represents Facebook instant article RSS feed as described on
 https://developers.facebook.com/docs/instant-articles/publishing/setup-rss-feed
 Use instant.NewFeed() to create initial struct with all headers set up and
@@ -0,0 +1,310 @@
+package instant
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ErrNoEmbedProvider is returned when no registered provider supports embed URL
+var ErrNoEmbedProvider = errors.New("No embed provider for URL")
+
+// EmbedProvider creates embed figures for URLs of single social network or video platform.
+// Custom providers can be added using RegisterEmbedProvider.
+type EmbedProvider interface {
+	// Match reports if provider can embed URL
+	Match(u *url.URL) bool
+	// Embed returns op-social or op-interactive figure for URL
+	Embed(u *url.URL) (Figure, error)
+}
+
+// EmbedProviderFunc adapts match and embed functions to EmbedProvider
+type EmbedProviderFunc struct {
+	MatchFunc func(u *url.URL) bool
+	EmbedFunc func(u *url.URL) (Figure, error)
+}
+
+// Match for EmbedProvider interface
+func (p EmbedProviderFunc) Match(u *url.URL) bool {
+	return p.MatchFunc(u)
+}
+
+// Embed for EmbedProvider interface
+func (p EmbedProviderFunc) Embed(u *url.URL) (Figure, error) {
+	return p.EmbedFunc(u)
+}
+
+type namedProvider struct {
+	name     string
+	provider EmbedProvider
+}
+
+var (
+	embedMu        sync.RWMutex
+	embedProviders = []namedProvider{
+		{"facebook", EmbedProviderFunc{matchHost("facebook.com", "fb.watch"), embedFacebook}},
+		{"twitter", EmbedProviderFunc{matchHost("twitter.com", "x.com"), embedTwitter}},
+		{"instagram", EmbedProviderFunc{matchHost("instagram.com"), embedInstagram}},
+		{"youtube", EmbedProviderFunc{matchHost("youtube.com", "youtu.be"), embedYouTube}},
+		{"vimeo", EmbedProviderFunc{matchHost("vimeo.com"), embedVimeo}},
+	}
+)
+
+// RegisterEmbedProvider adds provider to embed registry. Provider registered with
+// existing name replaces existing one. Custom providers are matched before built-in
+// providers facebook, twitter, instagram, youtube and vimeo.
+func RegisterEmbedProvider(name string, p EmbedProvider) {
+	embedMu.Lock()
+	defer embedMu.Unlock()
+	for i, np := range embedProviders {
+		if np.name == name {
+			embedProviders[i].provider = p
+			return
+		}
+	}
+	embedProviders = append([]namedProvider{{name, p}}, embedProviders...)
+}
+
+// NewEmbedFigure creates social or interactive embed figure for URL of Facebook post or video,
+// Twitter/X post, Instagram post, YouTube or Vimeo video, or any URL supported by registered provider.
+// Figure can be added to article with AddFigure or InsertFigure.
+// See https://developers.facebook.com/docs/instant-articles/reference/social for more info.
+func NewEmbedFigure(rawurl string) (Figure, error) {
+	u, err := url.Parse(rawurl)
+	if err != nil {
+		return Figure{}, err
+	}
+
+	embedMu.RLock()
+	defer embedMu.RUnlock()
+	for _, np := range embedProviders {
+		if np.provider.Match(u) {
+			return np.provider.Embed(u)
+		}
+	}
+	return Figure{}, ErrNoEmbedProvider
+}
+
+// AddEmbed adds social or interactive embed for URL to article content.
+// See NewEmbedFigure for supported URLs.
+func (a *Article) AddEmbed(rawurl string) error {
+	f, err := NewEmbedFigure(rawurl)
+	if err != nil {
+		return err
+	}
+	a.AddFigure(f)
+	return nil
+}
+
+// Interactive is op-interactive embed, like chart or data visualization, loaded from Src url
+// or inlined as HTML. Only one of Src and HTML should be set.
+// See https://developers.facebook.com/docs/instant-articles/reference/interactive for more info.
+type Interactive struct {
+	Src         string // url of interactive content
+	HTML        string // inline HTML, must be complete fragment with all elements closed
+	Width       int    // width in pixels
+	Height      int    // height in pixels
+	NoMargin    bool   // display embed in full screen width without margins
+	ColumnWidth bool   // display embed in column width with margins
+}
+
+// NewInteractiveFigure creates op-interactive figure which can be added to article with AddFigure or InsertFigure.
+func NewInteractiveFigure(i Interactive) (Figure, error) {
+	switch {
+	case i.Src == "" && strings.TrimSpace(i.HTML) == "":
+		return Figure{}, errors.New("Interactive embed requires src or inline HTML")
+	case i.Src != "" && i.HTML != "":
+		return Figure{}, errors.New("Interactive embed can't have both src and inline HTML")
+	case i.NoMargin && i.ColumnWidth:
+		return Figure{}, errors.New("Interactive embed can't be both no-margin and column-width")
+	case i.Width < 0 || i.Height < 0:
+		return Figure{}, errors.New("Interactive embed width and height can't be negative")
+	}
+	if i.HTML != "" {
+		if err := checkInlineHTML(i.HTML); err != nil {
+			return Figure{}, err
+		}
+	}
+
+	f := interactiveFigure(i.Src, "", "")
+	f.IFrame.Text = i.HTML
+	if i.Width > 0 {
+		f.IFrame.Width = strconv.Itoa(i.Width)
+	}
+	if i.Height > 0 {
+		f.IFrame.Height = strconv.Itoa(i.Height)
+	}
+	switch {
+	case i.NoMargin:
+		f.IFrame.Class = "no-margin"
+	case i.ColumnWidth:
+		f.IFrame.Class = "column-width"
+	}
+	return f, nil
+}
+
+// AddInteractive adds op-interactive embed to article content.
+func (a *Article) AddInteractive(i Interactive) error {
+	f, err := NewInteractiveFigure(i)
+	if err != nil {
+		return err
+	}
+	a.AddFigure(f)
+	return nil
+}
+
+// checkInlineHTML returns error if HTML can't be safely inlined in <iframe>,
+// i.e. if it has unclosed or unbalanced elements or it closes the iframe itself.
+func checkInlineHTML(html string) error {
+	z := newHTMLTokenizer(strings.NewReader(html))
+	var open []string
+	for {
+		// tokens are read as written, without implied end tags, so balance can be checked
+		t, empty, err := z.next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("Invalid inline HTML: %w", err)
+		}
+		switch t := t.(type) {
+		case xml.StartElement:
+			name := strings.ToLower(t.Name.Local)
+			if name == "iframe" && len(open) == 0 {
+				return errors.New("Invalid inline HTML: <iframe> is not allowed on top level")
+			}
+			if !isVoid(name) && !empty {
+				open = append(open, name)
+			}
+		case xml.EndElement:
+			name := strings.ToLower(t.Name.Local)
+			if isVoid(name) {
+				continue
+			}
+			if len(open) == 0 || open[len(open)-1] != name {
+				return fmt.Errorf("Invalid inline HTML: unexpected </%s>", name)
+			}
+			open = open[:len(open)-1]
+		}
+	}
+	if len(open) > 0 {
+		return fmt.Errorf("Invalid inline HTML: unclosed <%s>", open[len(open)-1])
+	}
+	return nil
+}
+
+// matchHost returns match function for URLs on hosts or their subdomains
+func matchHost(hosts ...string) func(u *url.URL) bool {
+	return func(u *url.URL) bool {
+		h := strings.ToLower(u.Hostname())
+		for _, host := range hosts {
+			if h == host || strings.HasSuffix(h, "."+host) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// socialFigure creates op-social figure with embed code
+func socialFigure(code string) Figure {
+	return Figure{
+		Class:  "op-social",
+		IFrame: &IFrame{Text: code},
+	}
+}
+
+// interactiveFigure creates op-interactive figure with iframe src
+func interactiveFigure(src, width, height string) Figure {
+	return Figure{
+		Class: "op-interactive",
+		IFrame: &IFrame{
+			Src:    src,
+			Width:  width,
+			Height: height,
+		},
+	}
+}
+
+// pathSegments returns non empty segments of URL path
+func pathSegments(u *url.URL) []string {
+	return strings.FieldsFunc(u.Path, func(r rune) bool { return r == '/' })
+}
+
+func embedFacebook(u *url.URL) (Figure, error) {
+	var class string
+	s := pathSegments(u)
+	switch {
+	case strings.HasSuffix(strings.ToLower(u.Hostname()), "fb.watch"):
+		if len(s) > 0 {
+			class = "fb-video"
+		}
+	case len(s) > 0 && s[0] == "watch" && (len(s) > 1 || u.Query().Get("v") != ""):
+		class = "fb-video"
+	case len(s) > 2 && s[1] == "videos":
+		class = "fb-video"
+	case len(s) > 2 && s[1] == "posts":
+		class = "fb-post"
+	case len(s) == 1 && s[0] == "permalink.php" && u.Query().Get("story_fbid") != "":
+		class = "fb-post"
+	}
+	if class == "" {
+		return Figure{}, errors.New("Facebook URL must be link to post or video")
+	}
+	return socialFigure(`<div class="` + class + `" data-href="` + attrEscaper.Replace(u.String()) + `"></div>`), nil
+}
+
+func embedTwitter(u *url.URL) (Figure, error) {
+	s := pathSegments(u)
+	if len(s) < 3 || s[1] != "status" {
+		return Figure{}, errors.New("Twitter URL must be link to post")
+	}
+	return socialFigure(`<blockquote class="twitter-tweet"><a href="https://twitter.com/` + url.PathEscape(s[0]) + `/status/` + url.PathEscape(s[2]) + `"></a></blockquote>` +
+		`<script async src="https://platform.twitter.com/widgets.js" charset="utf-8"></script>`), nil
+}
+
+func embedInstagram(u *url.URL) (Figure, error) {
+	s := pathSegments(u)
+	if len(s) < 2 || s[0] != "p" && s[0] != "reel" && s[0] != "tv" {
+		return Figure{}, errors.New("Instagram URL must be link to post")
+	}
+	permalink := "https://www.instagram.com/" + s[0] + "/" + url.PathEscape(s[1]) + "/"
+	return socialFigure(`<blockquote class="instagram-media" data-instgrm-permalink="` + permalink + `" data-instgrm-version="14"></blockquote>` +
+		`<script async src="https://www.instagram.com/embed.js"></script>`), nil
+}
+
+func embedYouTube(u *url.URL) (Figure, error) {
+	var id string
+	s := pathSegments(u)
+	switch {
+	case strings.HasSuffix(strings.ToLower(u.Hostname()), "youtu.be") && len(s) > 0:
+		id = s[0]
+	case len(s) > 1 && (s[0] == "embed" || s[0] == "shorts" || s[0] == "live"):
+		id = s[1]
+	default:
+		id = u.Query().Get("v")
+	}
+	if id == "" {
+		return Figure{}, errors.New("YouTube URL must be link to video")
+	}
+	return interactiveFigure("https://www.youtube.com/embed/"+url.PathEscape(id), "560", "315"), nil
+}
+
+func embedVimeo(u *url.URL) (Figure, error) {
+	s := pathSegments(u)
+	if len(s) == 0 {
+		return Figure{}, errors.New("Vimeo URL must be link to video")
+	}
+	id := s[len(s)-1]
+	for _, r := range id {
+		if r < '0' || r > '9' {
+			return Figure{}, errors.New("Vimeo URL must be link to video")
+		}
+	}
+	return interactiveFigure("https://player.vimeo.com/video/"+id, "640", "360"), nil
+}
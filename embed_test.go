@@ -0,0 +1,98 @@
+package instant_test
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/mileusna/facebook-instant-articles"
+)
+
+func TestEmbed(t *testing.T) {
+	tests := []struct {
+		url   string
+		class string
+		embed string
+	}{
+		{"https://www.facebook.com/mysite/posts/10153", "op-social", `<div class="fb-post" data-href="https://www.facebook.com/mysite/posts/10153"></div>`},
+		{"https://www.facebook.com/mysite/videos/10154/", "op-social", `<div class="fb-video" data-href="https://www.facebook.com/mysite/videos/10154/"></div>`},
+		{"https://www.facebook.com/permalink.php?story_fbid=10153&id=4", "op-social", `<div class="fb-post"`},
+		{"https://www.facebook.com/watch/?v=10154", "op-social", `<div class="fb-video"`},
+		{"https://fb.watch/abc123/", "op-social", `<div class="fb-video"`},
+		{"https://x.com/mysite/status/123456", "op-social", `<a href="https://twitter.com/mysite/status/123456"></a>`},
+		{"https://www.instagram.com/p/BXy7/?hl=en", "op-social", `data-instgrm-permalink="https://www.instagram.com/p/BXy7/"`},
+		{"https://www.youtube.com/watch?v=dQw4w9WgXcQ", "op-interactive", "https://www.youtube.com/embed/dQw4w9WgXcQ"},
+		{"https://youtu.be/dQw4w9WgXcQ", "op-interactive", "https://www.youtube.com/embed/dQw4w9WgXcQ"},
+		{"https://vimeo.com/76979871", "op-interactive", "https://player.vimeo.com/video/76979871"},
+	}
+	for _, test := range tests {
+		f, err := instant.NewEmbedFigure(test.url)
+		if err != nil {
+			t.Errorf("%s: %v", test.url, err)
+			continue
+		}
+		if f.Class != test.class || f.IFrame == nil || !strings.Contains(f.IFrame.Src+f.IFrame.Text, test.embed) {
+			t.Errorf("%s: unexpected figure %#v", test.url, f)
+		}
+	}
+
+	// provider matches, but url is not link to post or video
+	for _, u := range []string{"https://www.facebook.com/", "https://www.facebook.com/mysite", "https://fb.watch/", "https://x.com/mysite"} {
+		if _, err := instant.NewEmbedFigure(u); err == nil || err == instant.ErrNoEmbedProvider {
+			t.Errorf("%s: expected error, got %v", u, err)
+		}
+	}
+
+	if _, err := instant.NewEmbedFigure("https://mysite/video"); err != instant.ErrNoEmbedProvider {
+		t.Errorf("expected ErrNoEmbedProvider, got %v", err)
+	}
+
+	instant.RegisterEmbedProvider("mysite", instant.EmbedProviderFunc{
+		MatchFunc: func(u *url.URL) bool { return u.Host == "mysite" },
+		EmbedFunc: func(u *url.URL) (instant.Figure, error) {
+			return instant.Figure{Class: "op-interactive", IFrame: &instant.IFrame{Src: u.String()}}, nil
+		},
+	})
+	var a instant.Article
+	if err := a.AddEmbed("https://mysite/video"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestInteractive(t *testing.T) {
+	var a instant.Article
+	a.SetTitle("My article title")
+	a.SetCanonical("http://mysite/url-to-this-article")
+	if err := a.AddInteractive(instant.Interactive{Src: "http://mysite/chart", Width: 600, Height: 400, NoMargin: true}); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.AddInteractive(instant.Interactive{HTML: `<div id="chart"></div><script>for (i = 0; i<3; i++) draw("chart")</script>`, ColumnWidth: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	html, err := a.HTML()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{
+		`<figure class="op-interactive"><iframe src="http://mysite/chart" height="400" width="600" class="no-margin"></iframe></figure>`,
+		`<figure class="op-interactive"><iframe class="column-width"><div id="chart"></div><script>for (i = 0; i<3; i++) draw("chart")</script></iframe></figure>`,
+	} {
+		if !strings.Contains(string(html), s) {
+			t.Errorf("%s not found in %s", s, html)
+		}
+	}
+
+	for _, i := range []instant.Interactive{
+		{},
+		{Src: "http://mysite/chart", HTML: "<div></div>"},
+		{HTML: "<div><p>Unclosed</div>"},
+		{HTML: "<div>", NoMargin: true},
+		{HTML: `<iframe src="http://mysite/chart"></iframe>`},
+		{Src: "http://mysite/chart", NoMargin: true, ColumnWidth: true},
+	} {
+		if _, err := instant.NewInteractiveFigure(i); err == nil {
+			t.Errorf("expected error for %#v", i)
+		}
+	}
+}
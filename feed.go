@@ -4,7 +4,10 @@ import (
 	"bytes"
 	"crypto/md5"
 	"encoding/xml"
+	"errors"
 	"fmt"
+	"io"
+	"strings"
 )
 
 // Feed struct represents Facebook Instant Articles RSS feed.
@@ -32,6 +35,8 @@ type item struct {
 	Author      []string `xml:"author"`
 	PubDate     string   `xml:"pubDate"`
 	Encoded     []byte   `xml:",innerxml"`
+	Article     Article  `xml:"-"`
+	Err         error    `xml:"-"` // error of article parsed from RSS feed
 }
 
 // SetTitle of feed. Optional.
@@ -79,17 +84,13 @@ func (f *Feed) addArticle(a Article, guid string) error {
 		guid = fmt.Sprintf("%x", md5.Sum([]byte(a.Head.Link.Href)))
 	}
 
-	var buff bytes.Buffer
-	buff.WriteString("\n<content:encoded><![CDATA[\n")
-	buff.Write(b)
-	buff.WriteString("\n]]></content:encoded>")
-
 	i := item{
 		Title:       a.Body.Article.Header.H1,
 		Description: a.Body.Article.Header.H2,
 		Link:        a.Head.Link.Href,
 		GUID:        guid,
-		Encoded:     buff.Bytes(),
+		Encoded:     encoded(b),
+		Article:     a,
 	}
 
 	// if no subtitle, set first para as description
@@ -127,6 +128,36 @@ func (f *Feed) addArticle(a Article, guid string) error {
 	return nil
 }
 
+// encoded returns article html wrapped in <content:encoded> CDATA section
+func encoded(html []byte) []byte {
+	var buff bytes.Buffer
+	buff.WriteString("\n<content:encoded><![CDATA[\n")
+	buff.Write(html)
+	buff.WriteString("\n]]></content:encoded>")
+	return buff.Bytes()
+}
+
+// Articles returns articles added to feed or parsed from RSS feed.
+// Articles which couldn't be parsed are returned partially, see Errors.
+func (f *Feed) Articles() []Article {
+	var articles []Article
+	for _, i := range f.Channel.Item {
+		articles = append(articles, i.Article)
+	}
+	return articles
+}
+
+// Errors returns errors of articles which couldn't be parsed from RSS feed.
+func (f *Feed) Errors() []error {
+	var errs []error
+	for _, i := range f.Channel.Item {
+		if i.Err != nil {
+			errs = append(errs, i.Err)
+		}
+	}
+	return errs
+}
+
 // MarshalXML for xml.Marshaler interface
 func (f Feed) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
 	feed := struct {
@@ -153,3 +184,71 @@ func (f Feed) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
 func (f Feed) RSS() ([]byte, error) {
 	return xml.Marshal(f)
 }
+
+// ParseFeed reads Facebook instant articles RSS feed and decodes embedded articles.
+// Article which can't be parsed doesn't fail entire feed, its item keeps raw encoded
+// article and error is reported by Errors.
+func ParseFeed(r io.Reader) (Feed, error) {
+	var f Feed
+	err := xml.NewDecoder(r).Decode(&f)
+	return f, err
+}
+
+// UnmarshalXML for xml.Unmarshaler interface, unmarshal RSS feed to Feed struct.
+func (f *Feed) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	if start.Name.Local != "rss" {
+		return errors.New("Feed root element <rss> is required")
+	}
+	for _, attr := range start.Attr {
+		switch {
+		case attr.Name.Local == "version":
+			f.Version = attr.Value
+		case attr.Name.Space == "xmlns" && attr.Name.Local == "content":
+			f.Content = attr.Value
+		}
+	}
+
+	feed := struct {
+		Channel channel `xml:"channel"`
+	}{}
+	if err := d.DecodeElement(&feed, &start); err != nil {
+		return err
+	}
+	f.Channel = feed.Channel
+	return nil
+}
+
+// UnmarshalXML for xml.Unmarshaler interface, unmarshal RSS item and its encoded article.
+// Article parsing error is kept in item.
+func (i *item) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	v := struct {
+		Title       string   `xml:"title"`
+		GUID        string   `xml:"guid"`
+		Description string   `xml:"description"`
+		Link        string   `xml:"link"`
+		Author      []string `xml:"author"`
+		PubDate     string   `xml:"pubDate"`
+		Encoded     string   `xml:"encoded"`
+	}{}
+	if err := d.DecodeElement(&v, &start); err != nil {
+		return err
+	}
+
+	a, err := ParseArticle(strings.NewReader(v.Encoded))
+	if err != nil {
+		err = fmt.Errorf("item %q: %w", v.GUID, err)
+	}
+
+	*i = item{
+		Title:       v.Title,
+		GUID:        v.GUID,
+		Description: v.Description,
+		Link:        v.Link,
+		Author:      v.Author,
+		PubDate:     v.PubDate,
+		Encoded:     encoded([]byte(strings.TrimSpace(v.Encoded))),
+		Article:     a,
+		Err:         err,
+	}
+	return nil
+}
@@ -1,6 +1,7 @@
 package instant_test
 
 import (
+	"bytes"
 	"encoding/xml"
 	"testing"
 	"time"
@@ -31,3 +32,87 @@ func TestFeed(t *testing.T) {
 		t.Error(err)
 	}
 }
+
+func TestParseFeed(t *testing.T) {
+	var f instant.Feed
+	f.SetTitle("Title feed")
+	f.SetLink("http://www.mysite.com")
+
+	var a instant.Article
+	a.SetTitle("My article title")
+	a.SetCanonical("http://mysite/url-to-this-article")
+	a.SetPublish(time.Now())
+	a.AddAuthor("Michael", "", "")
+	a.SetContent("<p>Pragraph 1</p><p>paragraph 2</p>")
+	f.AddArticleWithGUID(a, "12333")
+
+	rss, err := f.RSS()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := instant.ParseFeed(bytes.NewReader(rss))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Channel.Title != "Title feed" || len(p.Channel.Item) != 1 {
+		t.Fatalf("unexpected channel %#v", p.Channel)
+	}
+	if i := p.Channel.Item[0]; i.GUID != "12333" || i.PubDate == "" || len(i.Author) != 1 {
+		t.Errorf("unexpected item %#v", i)
+	}
+	if articles := p.Articles(); len(articles) != 1 || articles[0].Body.Article.Header.H1 != "My article title" {
+		t.Errorf("unexpected articles %#v", articles)
+	}
+
+	rss2, err := p.RSS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(rss, rss2) {
+		t.Errorf("round trip mismatch\n%s\n%s", rss, rss2)
+	}
+}
+
+func TestParseFeedErrors(t *testing.T) {
+	var f instant.Feed
+	for _, guid := range []string{"1", "2"} {
+		var a instant.Article
+		a.SetTitle("My article title")
+		a.SetCanonical("http://mysite/url-to-this-article-" + guid)
+		a.SetPublish(time.Now())
+		a.SetContent("<p>Paragraph</p>")
+		f.AddArticleWithGUID(a, guid)
+	}
+	rss, err := f.RSS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// break first article
+	rss = bytes.Replace(rss, []byte("<!doctype html><html"), []byte("<!doctype html><div"), 1)
+
+	p, err := instant.ParseFeed(bytes.NewReader(rss))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(p.Channel.Item) != 2 {
+		t.Fatalf("expected 2 items, got %d", len(p.Channel.Item))
+	}
+	if errs := p.Errors(); len(errs) != 1 || p.Channel.Item[0].Err == nil || p.Channel.Item[1].Err != nil {
+		t.Errorf("unexpected errors %v", errs)
+	}
+	if i := p.Channel.Item[0]; !bytes.Contains(i.Encoded, []byte("<!doctype html><div")) {
+		t.Errorf("raw article not kept %s", i.Encoded)
+	}
+	if a := p.Articles(); a[1].Body.Article.Header.H1 != "My article title" {
+		t.Errorf("unexpected article %#v", a[1])
+	}
+
+	rss2, err := p.RSS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(rss, rss2) {
+		t.Errorf("round trip mismatch\n%s\n%s", rss, rss2)
+	}
+}
@@ -0,0 +1,381 @@
+package instant
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strconv"
+	"strings"
+)
+
+// Image and video presentation modes
+const (
+	ModeFullscreen     = "fullscreen"
+	ModeAspectFit      = "aspect-fit"
+	ModeAspectFitOnly  = "aspect-fit-only"
+	ModeNonInteractive = "non-interactive"
+)
+
+// Caption vertical positions
+const (
+	CaptionVerticalTop    = "op-vertical-top"
+	CaptionVerticalCenter = "op-vertical-center"
+	CaptionVerticalBottom = "op-vertical-bottom"
+)
+
+// Caption text alignments
+const (
+	CaptionAlignLeft   = "op-left"
+	CaptionAlignCenter = "op-center"
+	CaptionAlignRight  = "op-right"
+)
+
+// Caption font sizes
+const (
+	CaptionSmall      = "op-small"
+	CaptionMedium     = "op-medium"
+	CaptionLarge      = "op-large"
+	CaptionExtraLarge = "op-extra-large"
+)
+
+// ImageOptions for image figures.
+// See https://developers.facebook.com/docs/instant-articles/reference/image for more info.
+type ImageOptions struct {
+	Mode     string  // presentation mode, one of Mode... constants
+	Likes    bool    // enable likes on image
+	Comments bool    // enable comments on image
+	Width    int     // image width hint in pixels
+	Height   int     // image height hint in pixels
+	Geotag   *Geotag // location of image
+	Audio    *Audio  // ambient audio played with image
+}
+
+// NewImageFigure creates image figure which can be added to article with AddFigure or InsertFigure.
+// Caption can be empty string.
+func NewImageFigure(url, caption string, o ImageOptions) Figure {
+	img := &Img{Src: url}
+	if o.Width > 0 {
+		img.Width = strconv.Itoa(o.Width)
+	}
+	if o.Height > 0 {
+		img.Height = strconv.Itoa(o.Height)
+	}
+	return Figure{
+		Img:        img,
+		Audio:      o.Audio,
+		Geotag:     o.Geotag,
+		Figcaption: newCaption(caption),
+		Mode:       o.Mode,
+		Feedback:   feedback(o.Likes, o.Comments),
+	}
+}
+
+// VideoOptions for video figures.
+// See https://developers.facebook.com/docs/instant-articles/reference/video for more info.
+type VideoOptions struct {
+	Mode            string   // presentation mode, one of Mode... constants
+	Likes           bool     // enable likes on video
+	Comments        bool     // enable comments on video
+	Loop            bool     // play video in loop
+	Controls        bool     // show video controls
+	DisableAutoplay bool     // disable autoplay, sets data-fb-disable-autoplay
+	Poster          string   // url of image displayed before video starts
+	Sources         []Source // additional renditions of video, e.g. video/webm
+	Geotag          *Geotag  // location of video
+	Audio           *Audio   // audio played with video
+}
+
+// NewVideoFigure creates video figure which can be added to article with AddFigure or InsertFigure.
+// videoType in format video/mp4 See the list of supported formats here https://www.facebook.com/help/218673814818907
+// Caption can be empty string.
+func NewVideoFigure(url, videoType, caption string, o VideoOptions) Figure {
+	return Figure{
+		Video: &Video{
+			Source:          append([]Source{{Src: url, Type: videoType}}, o.Sources...),
+			Poster:          o.Poster,
+			Loop:            o.Loop,
+			Controls:        o.Controls,
+			DisableAutoplay: o.DisableAutoplay,
+		},
+		Audio:      o.Audio,
+		Geotag:     o.Geotag,
+		Figcaption: newCaption(caption),
+		Mode:       o.Mode,
+		Feedback:   feedback(o.Likes, o.Comments),
+	}
+}
+
+// UnmarshalXML for xml.Unmarshaler interface, only op-geotag script is decoded as geotag
+// and other scripts, e.g. embed scripts, are ignored.
+func (f *Figure) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type figure Figure // without UnmarshalXML method
+	v := struct {
+		*figure
+		Script []script `xml:"script"`
+	}{figure: (*figure)(f)}
+	if err := d.DecodeElement(&v, &start); err != nil {
+		return err
+	}
+	g, err := geotag(v.Script)
+	f.Geotag = g
+	return err
+}
+
+// MarshalXML for xml.Marshaler interface, boolean attributes are set only if true
+func (v Video) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	if v.Poster != "" {
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "poster"}, Value: v.Poster})
+	}
+	boolAttr(&start, "loop", v.Loop)
+	boolAttr(&start, "controls", v.Controls)
+	boolAttr(&start, "data-fb-disable-autoplay", v.DisableAutoplay)
+	sources := struct {
+		Source []Source `xml:"source"`
+	}{v.Source}
+	return e.EncodeElement(sources, start)
+}
+
+// UnmarshalXML for xml.Unmarshaler interface
+func (v *Video) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	for _, attr := range start.Attr {
+		on := attr.Value != "false"
+		switch attr.Name.Local {
+		case "poster":
+			v.Poster = attr.Value
+		case "loop":
+			v.Loop = on
+		case "controls":
+			v.Controls = on
+		case "data-fb-disable-autoplay":
+			v.DisableAutoplay = on
+		}
+	}
+	sources := struct {
+		Source []Source `xml:"source"`
+	}{}
+	if err := d.DecodeElement(&sources, &start); err != nil {
+		return err
+	}
+	v.Source = sources.Source
+	return nil
+}
+
+// Audio is <audio> element in image, video or standalone audio figure
+// See https://developers.facebook.com/docs/instant-articles/reference/audio for more info.
+type Audio struct {
+	Source   []Source // audio source, e.g. audio/mpeg
+	Title    string   // audio title
+	Autoplay bool     // start playing audio automatically
+}
+
+// NewAudio creates audio with single source.
+// audioType in format audio/mpeg.
+func NewAudio(url, audioType, title string, autoplay bool) *Audio {
+	return &Audio{
+		Source:   []Source{{Src: url, Type: audioType}},
+		Title:    title,
+		Autoplay: autoplay,
+	}
+}
+
+// AddAudio adds standalone audio figure, e.g. podcast, to article content.
+// Caption can be empty string.
+func (a *Article) AddAudio(audio Audio, caption string) {
+	a.AddFigure(Figure{
+		Audio:      &audio,
+		Figcaption: newCaption(caption),
+	})
+}
+
+// MarshalXML for xml.Marshaler interface
+func (au Audio) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	if au.Title != "" {
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "title"}, Value: au.Title})
+	}
+	boolAttr(&start, "autoplay", au.Autoplay)
+	sources := struct {
+		Source []Source `xml:"source"`
+	}{au.Source}
+	return e.EncodeElement(sources, start)
+}
+
+// UnmarshalXML for xml.Unmarshaler interface
+func (au *Audio) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	for _, attr := range start.Attr {
+		switch attr.Name.Local {
+		case "title":
+			au.Title = attr.Value
+		case "autoplay":
+			au.Autoplay = attr.Value != "false"
+		}
+	}
+	sources := struct {
+		Source []Source `xml:"source"`
+	}{}
+	if err := d.DecodeElement(&sources, &start); err != nil {
+		return err
+	}
+	au.Source = sources.Source
+	return nil
+}
+
+// boolAttr adds HTML boolean attribute to start element if on is true
+func boolAttr(start *xml.StartElement, name string, on bool) {
+	if on {
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: name}, Value: name})
+	}
+}
+
+// feedback returns data-feedback attribute value
+func feedback(likes, comments bool) string {
+	var s []string
+	if likes {
+		s = append(s, "fb:likes")
+	}
+	if comments {
+		s = append(s, "fb:comments")
+	}
+	return strings.Join(s, ",")
+}
+
+// Slideshow is <figure class="op-slideshow"> containing multiple image figures.
+// Each image figure can have its own caption.
+type Slideshow struct {
+	Figure     []Figure `xml:"figure"`
+	Geotag     *Geotag  `xml:"script,omitempty"`
+	Figcaption *Caption `xml:"figcaption,omitempty"`
+}
+
+// StartElement for ContentElement interface
+func (s Slideshow) StartElement() xml.StartElement {
+	return xml.StartElement{
+		Name: xml.Name{Local: "figure"},
+		Attr: []xml.Attr{{Name: xml.Name{Local: "class"}, Value: "op-slideshow"}},
+	}
+}
+
+// UnmarshalXML for xml.Unmarshaler interface, only op-geotag script is decoded as geotag
+func (s *Slideshow) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	v := struct {
+		Figure     []Figure `xml:"figure"`
+		Script     []script `xml:"script"`
+		Figcaption *Caption `xml:"figcaption"`
+	}{}
+	if err := d.DecodeElement(&v, &start); err != nil {
+		return err
+	}
+	g, err := geotag(v.Script)
+	*s = Slideshow{Figure: v.Figure, Geotag: g, Figcaption: v.Figcaption}
+	return err
+}
+
+// AddImage to slideshow. Caption can be empty string.
+func (s *Slideshow) AddImage(url, caption string) {
+	s.Figure = append(s.Figure, Figure{
+		Img:        &Img{Src: url},
+		Figcaption: newCaption(caption),
+	})
+}
+
+// AddSlideshow to article content.
+func (a *Article) AddSlideshow(s Slideshow) {
+	a.Body.Article.Content = append(a.Body.Article.Content, s)
+}
+
+// SetCoverSlideshow of instant article.
+func (a *Article) SetCoverSlideshow(s Slideshow) {
+	a.Body.Article.Header.Figure = append(a.Body.Article.Header.Figure, s)
+}
+
+// Caption represents <figcaption> of image, video, slideshow or map.
+// See https://developers.facebook.com/docs/instant-articles/reference/caption for more info.
+type Caption struct {
+	Title    string // caption title rendered as <h1>
+	Text     string // caption text, can contain inline html
+	Credit   string // credit rendered as <cite>, e.g. photographer name
+	Position string // vertical position, one of CaptionVertical... constants
+	Align    string // text alignment, one of CaptionAlign... constants
+	Size     string // font size, one of CaptionSmall, CaptionMedium, CaptionLarge or CaptionExtraLarge
+}
+
+// newCaption returns caption with text or nil if text is empty
+func newCaption(text string) *Caption {
+	if text == "" {
+		return nil
+	}
+	return &Caption{Text: text}
+}
+
+// SetCoverCaption sets caption of cover image, video or slideshow.
+// Cover must be set before caption.
+func (a *Article) SetCoverCaption(c Caption) {
+	for i := len(a.Body.Article.Header.Figure) - 1; i >= 0; i-- {
+		switch f := a.Body.Article.Header.Figure[i].(type) {
+		case Figure:
+			if f.Class == "op-ad" {
+				continue
+			}
+			f.Figcaption = &c
+			a.Body.Article.Header.Figure[i] = f
+		case Slideshow:
+			f.Figcaption = &c
+			a.Body.Article.Header.Figure[i] = f
+		default:
+			continue
+		}
+		return
+	}
+}
+
+// MarshalXML for xml.Marshaler interface
+func (c Caption) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	v := struct {
+		Class string `xml:"class,attr,omitempty"`
+		H1    string `xml:"h1,omitempty"`
+		Text  string `xml:",innerxml"`
+		Cite  string `xml:"cite,omitempty"`
+	}{
+		Class: strings.Join(strings.Fields(c.Position+" "+c.Align+" "+c.Size), " "),
+		H1:    c.Title,
+		Text:  c.Text,
+		Cite:  c.Credit,
+	}
+	return e.EncodeElement(v, start)
+}
+
+// UnmarshalXML for xml.Unmarshaler interface
+func (c *Caption) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	for _, attr := range start.Attr {
+		if attr.Name.Local != "class" {
+			continue
+		}
+		for _, class := range strings.Fields(attr.Value) {
+			switch class {
+			case CaptionVerticalTop, CaptionVerticalCenter, CaptionVerticalBottom:
+				c.Position = class
+			case CaptionAlignLeft, CaptionAlignCenter, CaptionAlignRight:
+				c.Align = class
+			case CaptionSmall, CaptionMedium, CaptionLarge, CaptionExtraLarge:
+				c.Size = class
+			}
+		}
+	}
+
+	var buff bytes.Buffer
+	err := walkTokens(d, func(t xml.Token) error {
+		switch t := t.(type) {
+		case xml.StartElement:
+			switch strings.ToLower(t.Name.Local) {
+			case "h1":
+				return decodeText(d, &t, &c.Title)
+			case "cite":
+				return decodeText(d, &t, &c.Credit)
+			}
+			return writeElement(d, &buff, t)
+		default:
+			writeToken(&buff, t)
+		}
+		return nil
+	})
+	c.Text = strings.TrimSpace(buff.String())
+	return err
+}
@@ -0,0 +1,170 @@
+package instant_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/mileusna/facebook-instant-articles"
+)
+
+func TestSlideshow(t *testing.T) {
+	var s instant.Slideshow
+	s.AddImage("http://mysite/1.jpg", "First")
+	s.AddImage("http://mysite/2.jpg", "")
+	s.Figcaption = &instant.Caption{Text: "Gallery"}
+
+	var a instant.Article
+	a.SetTitle("My article title")
+	a.SetCanonical("http://mysite/url-to-this-article")
+	a.SetCoverSlideshow(s)
+	a.AddSlideshow(s)
+
+	html, err := a.HTML()
+	if err != nil {
+		t.Fatal(err)
+	}
+	slideshow := `<figure class="op-slideshow"><figure><img src="http://mysite/1.jpg"></img><figcaption>First</figcaption></figure><figure><img src="http://mysite/2.jpg"></img></figure><figcaption>Gallery</figcaption></figure>`
+	if strings.Count(string(html), slideshow) != 2 {
+		t.Errorf("slideshows not found in %s", html)
+	}
+
+	b, err := instant.ParseArticle(bytes.NewReader(html))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(b.Body.Article.Header.Figure) != 1 || len(b.Body.Article.Content) != 1 {
+		t.Fatalf("unexpected header figures %#v or content %#v", b.Body.Article.Header.Figure, b.Body.Article.Content)
+	}
+	if s, ok := b.Body.Article.Content[0].(instant.Slideshow); !ok || len(s.Figure) != 2 || s.Figcaption == nil || s.Figcaption.Text != "Gallery" {
+		t.Errorf("unexpected slideshow %#v", b.Body.Article.Content[0])
+	}
+}
+
+func TestCaption(t *testing.T) {
+	var a instant.Article
+	a.SetTitle("My article title")
+	a.SetCanonical("http://mysite/url-to-this-article")
+	a.SetCoverImage("http://mysite/cover.jpg", "")
+	a.SetCoverCaption(instant.Caption{
+		Title:    "Sunset",
+		Text:     "Over the <b>sea</b>",
+		Credit:   "Photo: Michael",
+		Position: instant.CaptionVerticalBottom,
+		Align:    instant.CaptionAlignRight,
+	})
+
+	html, err := a.HTML()
+	if err != nil {
+		t.Fatal(err)
+	}
+	caption := `<figcaption class="op-vertical-bottom op-right"><h1>Sunset</h1>Over the <b>sea</b><cite>Photo: Michael</cite></figcaption>`
+	if !strings.Contains(string(html), caption) {
+		t.Fatalf("caption not found in %s", html)
+	}
+
+	b, err := instant.ParseArticle(bytes.NewReader(html))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f, ok := b.Body.Article.Header.Figure[0].(instant.Figure); !ok || f.Figcaption == nil || *f.Figcaption != (instant.Caption{
+		Title:    "Sunset",
+		Text:     "Over the <b>sea</b>",
+		Credit:   "Photo: Michael",
+		Position: instant.CaptionVerticalBottom,
+		Align:    instant.CaptionAlignRight,
+	}) {
+		t.Errorf("unexpected cover figure %#v", b.Body.Article.Header.Figure[0])
+	}
+}
+
+func TestImageOptions(t *testing.T) {
+	var a instant.Article
+	a.SetTitle("My article title")
+	a.SetCanonical("http://mysite/url-to-this-article")
+	a.SetCoverImageWithOptions("http://mysite/cover.jpg", "", instant.ImageOptions{Mode: instant.ModeAspectFit, Likes: true, Comments: true})
+	a.AddFigure(instant.NewImageFigure("http://mysite/img.jpg", "Image", instant.ImageOptions{Mode: instant.ModeNonInteractive, Width: 640, Height: 480}))
+
+	html, err := a.HTML()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{
+		`<figure data-mode="aspect-fit" data-feedback="fb:likes,fb:comments"><img src="http://mysite/cover.jpg"></img></figure>`,
+		`<figure data-mode="non-interactive"><img src="http://mysite/img.jpg" width="640" height="480"></img><figcaption>Image</figcaption></figure>`,
+	} {
+		if !strings.Contains(string(html), s) {
+			t.Errorf("%s not found in %s", s, html)
+		}
+	}
+
+	b, err := instant.ParseArticle(bytes.NewReader(html))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f, ok := b.Body.Article.Content[0].(instant.Figure); !ok || f.Mode != instant.ModeNonInteractive || f.Img.Width != "640" {
+		t.Errorf("unexpected figure %#v", b.Body.Article.Content[0])
+	}
+}
+
+func TestVideoOptions(t *testing.T) {
+	var a instant.Article
+	a.SetTitle("My article title")
+	a.SetCanonical("http://mysite/url-to-this-article")
+	a.SetCoverVideoWithOptions("http://mysite/video.mp4", "video/mp4", "", instant.VideoOptions{
+		Mode:            instant.ModeAspectFitOnly,
+		Loop:            true,
+		DisableAutoplay: true,
+		Poster:          "http://mysite/poster.jpg",
+		Sources:         []instant.Source{{Src: "http://mysite/video.webm", Type: "video/webm"}},
+	})
+
+	html, err := a.HTML()
+	if err != nil {
+		t.Fatal(err)
+	}
+	video := `<figure data-mode="aspect-fit-only"><video poster="http://mysite/poster.jpg" loop="loop" data-fb-disable-autoplay="data-fb-disable-autoplay"><source src="http://mysite/video.mp4" type="video/mp4"></source><source src="http://mysite/video.webm" type="video/webm"></source></video></figure>`
+	if !strings.Contains(string(html), video) {
+		t.Fatalf("video not found in %s", html)
+	}
+
+	b, err := instant.ParseArticle(strings.NewReader(strings.Replace(string(html), `loop="loop"`, "loop", 1)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, ok := b.Body.Article.Header.Figure[0].(instant.Figure)
+	if !ok || f.Video == nil || !f.Video.Loop || !f.Video.DisableAutoplay || f.Video.Controls || len(f.Video.Source) != 2 || f.Video.Poster != "http://mysite/poster.jpg" {
+		t.Errorf("unexpected cover figure %#v", b.Body.Article.Header.Figure[0])
+	}
+}
+
+func TestAudio(t *testing.T) {
+	var a instant.Article
+	a.SetTitle("My article title")
+	a.SetCanonical("http://mysite/url-to-this-article")
+	a.SetCoverImageWithOptions("http://mysite/cover.jpg", "", instant.ImageOptions{
+		Audio: instant.NewAudio("http://mysite/ambient.mp3", "audio/mpeg", "Ambient", true),
+	})
+	a.AddAudio(*instant.NewAudio("http://mysite/podcast.mp3", "audio/mpeg", "Podcast", false), "Episode 1")
+
+	html, err := a.HTML()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{
+		`<figure><img src="http://mysite/cover.jpg"></img><audio title="Ambient" autoplay="autoplay"><source src="http://mysite/ambient.mp3" type="audio/mpeg"></source></audio></figure>`,
+		`<figure><audio title="Podcast"><source src="http://mysite/podcast.mp3" type="audio/mpeg"></source></audio><figcaption>Episode 1</figcaption></figure>`,
+	} {
+		if !strings.Contains(string(html), s) {
+			t.Errorf("%s not found in %s", s, html)
+		}
+	}
+
+	b, err := instant.ParseArticle(bytes.NewReader(html))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f, ok := b.Body.Article.Header.Figure[0].(instant.Figure); !ok || f.Audio == nil || !f.Audio.Autoplay || f.Audio.Title != "Ambient" {
+		t.Errorf("unexpected cover figure %#v", b.Body.Article.Header.Figure[0])
+	}
+}
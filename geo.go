@@ -0,0 +1,224 @@
+package instant
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Map styles
+const (
+	MapStyleStandard  = "standard"
+	MapStyleSatellite = "satellite"
+)
+
+// Location is single point on map.
+// See https://developers.facebook.com/docs/instant-articles/reference/map for more info.
+type Location struct {
+	Latitude  float64
+	Longitude float64
+	Title     string // location title displayed on map
+	Radius    int    // radius around location in meters, determines map zoom
+	Pivot     bool   // center map on this location
+	Style     string // MapStyleStandard or MapStyleSatellite
+}
+
+// Geotag is GeoJSON embedded as <script type="application/json" class="op-geotag">.
+// Single location is encoded as GeoJSON Feature, multiple locations as FeatureCollection.
+type Geotag struct {
+	Locations []Location
+}
+
+// Map is <figure class="op-map"> with GeoJSON locations
+type Map struct {
+	Geotag     Geotag   `xml:"script"`
+	Figcaption *Caption `xml:"figcaption,omitempty"`
+}
+
+// geoObject is GeoJSON Feature or FeatureCollection
+type geoObject struct {
+	Type       string         `json:"type"`
+	Geometry   *geoGeometry   `json:"geometry,omitempty"`
+	Properties *geoProperties `json:"properties,omitempty"`
+	Features   []geoObject    `json:"features,omitempty"`
+}
+
+type geoGeometry struct {
+	Type        string     `json:"type"`
+	Coordinates [2]float64 `json:"coordinates"`
+}
+
+type geoProperties struct {
+	Title  string `json:"title,omitempty"`
+	Radius int    `json:"radius,omitempty"`
+	Pivot  bool   `json:"pivot,omitempty"`
+	Style  string `json:"style,omitempty"`
+}
+
+// StartElement for ContentElement interface
+func (m Map) StartElement() xml.StartElement {
+	return xml.StartElement{
+		Name: xml.Name{Local: "figure"},
+		Attr: []xml.Attr{{Name: xml.Name{Local: "class"}, Value: "op-map"}},
+	}
+}
+
+// AddMap to article content.
+func (a *Article) AddMap(m Map) {
+	a.Body.Article.Content = append(a.Body.Article.Content, m)
+}
+
+// GeoJSON returns locations encoded as GeoJSON.
+// Coordinates are written in [latitude, longitude] order as expected by Facebook.
+func (g Geotag) GeoJSON() ([]byte, error) {
+	features := make([]geoObject, len(g.Locations))
+	for i, l := range g.Locations {
+		features[i] = geoObject{
+			Type: "Feature",
+			Geometry: &geoGeometry{
+				Type:        "Point",
+				Coordinates: [2]float64{l.Latitude, l.Longitude},
+			},
+			Properties: &geoProperties{
+				Title:  l.Title,
+				Radius: l.Radius,
+				Pivot:  l.Pivot,
+				Style:  l.Style,
+			},
+		}
+	}
+	if len(features) == 1 {
+		return json.Marshal(features[0])
+	}
+	return json.Marshal(geoObject{Type: "FeatureCollection", Features: features})
+}
+
+// parseGeoJSON decodes locations from GeoJSON Feature or FeatureCollection
+func parseGeoJSON(b []byte) ([]Location, error) {
+	var o geoObject
+	if err := json.Unmarshal(b, &o); err != nil {
+		return nil, err
+	}
+	features := o.Features
+	if o.Type == "Feature" {
+		features = []geoObject{o}
+	}
+	var locations []Location
+	for _, f := range features {
+		if f.Geometry == nil || f.Geometry.Type != "Point" {
+			continue
+		}
+		l := Location{
+			Latitude:  f.Geometry.Coordinates[0],
+			Longitude: f.Geometry.Coordinates[1],
+		}
+		if p := f.Properties; p != nil {
+			l.Title = p.Title
+			l.Radius = p.Radius
+			l.Pivot = p.Pivot
+			l.Style = p.Style
+		}
+		locations = append(locations, l)
+	}
+	return locations, nil
+}
+
+// script is <script> element in figure, only op-geotag script contains GeoJSON
+type script struct {
+	Type  string `xml:"type,attr"`
+	Class string `xml:"class,attr"`
+	Text  string `xml:",chardata"`
+}
+
+// geotag decodes locations from op-geotag script. Other scripts, e.g. embed scripts, are ignored.
+// Returns nil if there is no op-geotag script.
+func geotag(scripts []script) (*Geotag, error) {
+	for _, s := range scripts {
+		if s.Type != "application/json" || !strings.Contains(" "+s.Class+" ", " op-geotag ") {
+			continue
+		}
+		locations, err := parseGeoJSON([]byte(s.Text))
+		if err != nil {
+			return nil, err
+		}
+		return &Geotag{Locations: locations}, nil
+	}
+	return nil, nil
+}
+
+// UnmarshalXML for xml.Unmarshaler interface
+func (m *Map) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	v := struct {
+		Script     []script `xml:"script"`
+		Figcaption *Caption `xml:"figcaption"`
+	}{}
+	if err := d.DecodeElement(&v, &start); err != nil {
+		return err
+	}
+	g, err := geotag(v.Script)
+	if g != nil {
+		m.Geotag = *g
+	}
+	m.Figcaption = v.Figcaption
+	return err
+}
+
+// NewGeotag creates geotag for image, video or slideshow figure.
+func NewGeotag(locations ...Location) *Geotag {
+	return &Geotag{Locations: locations}
+}
+
+// check returns error if geotag can't be encoded to valid GeoJSON
+func (g Geotag) check() error {
+	if len(g.Locations) == 0 {
+		return errors.New("Geotag has no locations")
+	}
+	for i, l := range g.Locations {
+		if !validCoordinates(l.Latitude, l.Longitude) {
+			return fmt.Errorf("Geotag location %d has invalid coordinates [%g, %g]", i, l.Latitude, l.Longitude)
+		}
+	}
+	return nil
+}
+
+// validCoordinates reports if latitude and longitude are within valid range
+func validCoordinates(lat, lng float64) bool {
+	return lat >= -90 && lat <= 90 && lng >= -180 && lng <= 180
+}
+
+// MarshalXML for xml.Marshaler interface, geotag is checked before it is embedded.
+func (g Geotag) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	if err := g.check(); err != nil {
+		return err
+	}
+	b, err := g.GeoJSON()
+	if err != nil {
+		return err
+	}
+	script := struct {
+		Type  string `xml:"type,attr"`
+		Class string `xml:"class,attr"`
+		JSON  []byte `xml:",innerxml"`
+	}{
+		Type:  "application/json",
+		Class: "op-geotag",
+		JSON:  b, // json.Marshal escapes <, > and &, so it is safe as raw script content
+	}
+	return e.EncodeElement(script, xml.StartElement{Name: xml.Name{Local: "script"}})
+}
+
+// UnmarshalXML for xml.Unmarshaler interface
+func (g *Geotag) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	var s string
+	if err := decodeText(d, &start, &s); err != nil {
+		return err
+	}
+	locations, err := parseGeoJSON([]byte(s))
+	if err != nil {
+		return err
+	}
+	g.Locations = locations
+	return nil
+}
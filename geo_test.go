@@ -0,0 +1,107 @@
+package instant_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/mileusna/facebook-instant-articles"
+)
+
+func TestMap(t *testing.T) {
+	var a instant.Article
+	a.SetTitle("My article title")
+	a.SetCanonical("http://mysite/url-to-this-article")
+	a.AddMap(instant.Map{
+		Geotag: instant.Geotag{Locations: []instant.Location{
+			{Latitude: 44.8125, Longitude: 20.4612, Title: "Belgrade", Radius: 5000, Pivot: true, Style: instant.MapStyleSatellite},
+		}},
+		Figcaption: &instant.Caption{Text: "Belgrade"},
+	})
+
+	html, err := a.HTML()
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := `<figure class="op-map"><script type="application/json" class="op-geotag">{"type":"Feature","geometry":{"type":"Point","coordinates":[44.8125,20.4612]},"properties":{"title":"Belgrade","radius":5000,"pivot":true,"style":"satellite"}}</script><figcaption>Belgrade</figcaption></figure>`
+	if !strings.Contains(string(html), m) {
+		t.Fatalf("map not found in %s", html)
+	}
+
+	b, err := instant.ParseArticle(bytes.NewReader(html))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m, ok := b.Body.Article.Content[0].(instant.Map); !ok || len(m.Geotag.Locations) != 1 || m.Geotag.Locations[0].Title != "Belgrade" || m.Geotag.Locations[0].Longitude != 20.4612 {
+		t.Errorf("unexpected map %#v", b.Body.Article.Content[0])
+	}
+
+	g := instant.Geotag{Locations: []instant.Location{{Latitude: 1, Longitude: 2}, {Latitude: 3, Longitude: 4}}}
+	geojson, err := g.GeoJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(geojson), `{"type":"FeatureCollection","features":[`) {
+		t.Errorf("unexpected GeoJSON %s", geojson)
+	}
+}
+
+func TestGeotag(t *testing.T) {
+	var a instant.Article
+	a.SetTitle("My article title")
+	a.SetCanonical("http://mysite/url-to-this-article")
+	a.SetCoverImageWithOptions("http://mysite/cover.jpg", "", instant.ImageOptions{
+		Geotag: instant.NewGeotag(instant.Location{Latitude: 44.8125, Longitude: 20.4612}),
+	})
+
+	html, err := a.HTML()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cover := `<figure><img src="http://mysite/cover.jpg"></img><script type="application/json" class="op-geotag">{"type":"Feature","geometry":{"type":"Point","coordinates":[44.8125,20.4612]},"properties":{}}</script></figure>`
+	if !strings.Contains(string(html), cover) {
+		t.Fatalf("geotag not found in %s", html)
+	}
+
+	b, err := instant.ParseArticle(bytes.NewReader(html))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f, ok := b.Body.Article.Header.Figure[0].(instant.Figure); !ok || f.Geotag == nil || len(f.Geotag.Locations) != 1 {
+		t.Errorf("unexpected cover figure %#v", b.Body.Article.Header.Figure[0])
+	}
+
+	a.AddFigure(instant.NewImageFigure("http://mysite/img.jpg", "", instant.ImageOptions{
+		Geotag: instant.NewGeotag(instant.Location{Latitude: 120, Longitude: 20}),
+	}))
+	if _, err := a.HTML(); err == nil {
+		t.Error("expected error for invalid coordinates")
+	}
+}
+
+func TestGeotagScripts(t *testing.T) {
+	html := `<p>intro</p>` +
+		`<figure><img src="http://mysite/img.jpg"><script async src="https://platform.twitter.com/widgets.js"></script>` +
+		`<script type="application/json" class="op-geotag">{"type":"Feature","geometry":{"type":"Point","coordinates":[44.8125,20.4612]}}</script></figure>` +
+		`<figure class="wp-block-embed"><blockquote class="twitter-tweet"><a href="https://twitter.com/x/status/1">Tweet</a></blockquote>` +
+		`<script async src="https://platform.twitter.com/widgets.js" charset="utf-8"></script></figure>` +
+		`<figure class="op-slideshow"><figure><img src="http://mysite/1.jpg"></figure><script src="slides.js"></script></figure>` +
+		`<p>outro</p>`
+
+	c, _, err := instant.ParseContent(html)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(c) != 5 {
+		t.Fatalf("expected 5 content tags, got %#v", c)
+	}
+	if f, ok := c[1].(instant.Figure); !ok || f.Geotag == nil || len(f.Geotag.Locations) != 1 || f.Geotag.Locations[0].Latitude != 44.8125 {
+		t.Errorf("unexpected geotagged figure %#v", c[1])
+	}
+	if f, ok := c[2].(instant.Figure); !ok || f.Geotag != nil {
+		t.Errorf("unexpected embed figure %#v", c[2])
+	}
+	if s, ok := c[3].(instant.Slideshow); !ok || s.Geotag != nil || len(s.Figure) != 1 {
+		t.Errorf("unexpected slideshow %#v", c[3])
+	}
+}
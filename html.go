@@ -0,0 +1,340 @@
+package instant
+
+import (
+	"bytes"
+	"encoding/xml"
+	"html"
+	"io"
+	"io/ioutil"
+	"strings"
+)
+
+// elements without end tag
+var voidTags = map[string]bool{
+	"area": true, "base": true, "br": true, "col": true, "embed": true, "hr": true, "img": true,
+	"input": true, "link": true, "meta": true, "param": true, "source": true, "track": true, "wbr": true,
+}
+
+// elements whose content is raw text and not HTML
+var rawTextTags = map[string]bool{
+	"script": true, "style": true,
+}
+
+// elements which close open <p>
+var closePTags = map[string]bool{
+	"address": true, "article": true, "aside": true, "blockquote": true, "dd": true, "details": true,
+	"div": true, "dl": true, "dt": true, "fieldset": true, "figcaption": true, "figure": true,
+	"footer": true, "form": true, "h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true,
+	"header": true, "hr": true, "li": true, "main": true, "nav": true, "ol": true, "p": true,
+	"pre": true, "section": true, "table": true, "ul": true,
+}
+
+// elements which limit implied and stray end tags to their content
+var scopeTags = map[string]bool{
+	"applet": true, "button": true, "caption": true, "html": true, "iframe": true, "marquee": true,
+	"object": true, "table": true, "td": true, "template": true, "th": true,
+}
+
+// bareAttr is name space of attributes written without value, like async, so they are written back the same way.
+// Attribute value is set to attribute name.
+const bareAttr = "\x00bare"
+
+// htmlTokenizer reads HTML and returns balanced tokens, so HTML can be decoded with xml.Decoder.
+// Script and style content is returned as raw text, end tags implied by HTML are added,
+// stray end tags are ignored and elements left open are closed at the end of input.
+type htmlTokenizer struct {
+	src    []byte
+	pos    int
+	err    error
+	offset int64       // source offset of last token read
+	raw    string      // open script or style element
+	open   []xml.Name  // open elements
+	queue  []xml.Token // tokens ready to be returned
+}
+
+// newHTMLTokenizer reads entire r and returns tokenizer for it
+func newHTMLTokenizer(r io.Reader) *htmlTokenizer {
+	src, err := ioutil.ReadAll(r)
+	return &htmlTokenizer{src: src, err: err}
+}
+
+// newHTMLDecoder returns xml.Decoder which reads HTML instead of strict XML
+func newHTMLDecoder(r io.Reader) *xml.Decoder {
+	return xml.NewTokenDecoder(newHTMLTokenizer(r))
+}
+
+// Token for xml.TokenReader interface
+func (z *htmlTokenizer) Token() (xml.Token, error) {
+	for len(z.queue) == 0 {
+		t, empty, err := z.next()
+		if err == io.EOF && len(z.open) > 0 {
+			z.close(0)
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		z.build(t, empty)
+	}
+	t := z.queue[0]
+	z.queue = z.queue[1:]
+	return t, nil
+}
+
+// build queues token along with end tags implied by it
+func (z *htmlTokenizer) build(t xml.Token, empty bool) {
+	switch t := t.(type) {
+	case xml.StartElement:
+		name := strings.ToLower(t.Name.Local)
+		switch name {
+		case "li":
+			z.closeImplied("li", "ol", "ul")
+		case "dt", "dd":
+			z.closeImplied("dt", "dl")
+			z.closeImplied("dd", "dl")
+		}
+		if closePTags[name] {
+			z.closeImplied("p")
+		}
+		z.queue = append(z.queue, t)
+		if empty || voidTags[name] {
+			z.queue = append(z.queue, t.End())
+			return
+		}
+		z.open = append(z.open, t.Name)
+	case xml.EndElement:
+		// end tags which don't match open element are ignored
+		if i := z.find(t.Name.Local); i >= 0 {
+			z.close(i)
+		}
+	default:
+		z.queue = append(z.queue, t)
+	}
+}
+
+// find returns index of open element with name, or -1 if element isn't open in current scope
+func (z *htmlTokenizer) find(name string, stop ...string) int {
+	for i := len(z.open) - 1; i >= 0; i-- {
+		open := strings.ToLower(z.open[i].Local)
+		if open == strings.ToLower(name) {
+			return i
+		}
+		if scopeTags[open] {
+			return -1
+		}
+		for _, s := range stop {
+			if open == s {
+				return -1
+			}
+		}
+	}
+	return -1
+}
+
+// closeImplied closes open element with name, unless one of stop elements is open after it
+func (z *htmlTokenizer) closeImplied(name string, stop ...string) {
+	if i := z.find(name, stop...); i >= 0 {
+		z.close(i)
+	}
+}
+
+// close queues end elements for open elements from i to the top
+func (z *htmlTokenizer) close(i int) {
+	for j := len(z.open) - 1; j >= i; j-- {
+		z.queue = append(z.queue, xml.EndElement{Name: z.open[j]})
+	}
+	z.open = z.open[:i]
+}
+
+// next reads single token from source as it is written, without balancing elements.
+// empty is true for self-closed elements like <div />.
+func (z *htmlTokenizer) next() (t xml.Token, empty bool, err error) {
+	if z.err != nil {
+		return nil, false, z.err
+	}
+	if z.pos >= len(z.src) {
+		return nil, false, io.EOF
+	}
+	z.offset = int64(z.pos)
+	if z.raw != "" {
+		return z.rawText(), false, nil
+	}
+
+	s := z.src[z.pos:]
+	if isTagStart(s) {
+		switch {
+		case bytes.HasPrefix(s, []byte("<!--")):
+			end := bytes.Index(s[4:], []byte("-->"))
+			if end < 0 {
+				z.pos = len(z.src)
+				return xml.Comment(copyBytes(s[4:])), false, nil
+			}
+			z.pos += 4 + end + 3
+			return xml.Comment(copyBytes(s[4 : 4+end])), false, nil
+		case s[1] == '!' || s[1] == '?':
+			end := bytes.IndexByte(s, '>')
+			if end < 0 {
+				end = len(s)
+			}
+			z.pos += end + 1
+			if s[1] == '?' {
+				// processing instructions are treated as comments in HTML
+				return xml.Comment(copyBytes(s[1:end])), false, nil
+			}
+			return xml.Directive(copyBytes(s[2:end])), false, nil
+		case s[1] == '/':
+			z.pos += 2
+			name := z.name()
+			if end := bytes.IndexByte(z.src[z.pos:], '>'); end >= 0 {
+				z.pos += end + 1
+			} else {
+				z.pos = len(z.src)
+			}
+			return xml.EndElement{Name: xml.Name{Local: name}}, false, nil
+		default:
+			return z.startTag()
+		}
+	}
+
+	i := 1
+	for i < len(s) && !(s[i] == '<' && isTagStart(s[i:])) {
+		i++
+	}
+	z.pos += i
+	return xml.CharData(html.UnescapeString(string(s[:i]))), false, nil
+}
+
+// startTag reads element name and attributes
+func (z *htmlTokenizer) startTag() (xml.StartElement, bool, error) {
+	z.pos++
+	t := xml.StartElement{Name: xml.Name{Local: z.name()}}
+	empty := false
+	for {
+		z.skipSpace()
+		if z.pos >= len(z.src) {
+			break
+		}
+		if c := z.src[z.pos]; c == '>' {
+			z.pos++
+			break
+		} else if c == '/' {
+			z.pos++
+			if z.pos < len(z.src) && z.src[z.pos] == '>' {
+				z.pos++
+				empty = true
+				break
+			}
+			continue
+		}
+
+		name := z.name()
+		if name == "" {
+			z.pos++
+			continue
+		}
+		// attributes without value, like async, are set to their name
+		attr := xml.Attr{Name: xml.Name{Space: bareAttr, Local: name}, Value: name}
+		z.skipSpace()
+		if z.pos < len(z.src) && z.src[z.pos] == '=' {
+			z.pos++
+			z.skipSpace()
+			attr = xml.Attr{Name: xml.Name{Local: name}, Value: z.attrValue()}
+		}
+		t.Attr = append(t.Attr, attr)
+	}
+	if rawTextTags[strings.ToLower(t.Name.Local)] && !empty {
+		z.raw = t.Name.Local
+	}
+	return t, empty, nil
+}
+
+// rawText reads script or style content until its end tag
+func (z *htmlTokenizer) rawText() xml.Token {
+	s := z.src[z.pos:]
+	end := len(s)
+	for i := 0; i < len(s); {
+		j := bytes.Index(s[i:], []byte("</"))
+		if j < 0 {
+			break
+		}
+		i += j
+		name := s[i+2:]
+		if len(name) >= len(z.raw) && strings.EqualFold(string(name[:len(z.raw)]), z.raw) &&
+			(len(name) == len(z.raw) || isNameEnd(name[len(z.raw)])) {
+			end = i
+			break
+		}
+		i += 2
+	}
+	z.raw = ""
+	z.pos += end
+	return xml.CharData(copyBytes(s[:end]))
+}
+
+// name reads element or attribute name
+func (z *htmlTokenizer) name() string {
+	start := z.pos
+	for z.pos < len(z.src) && !isNameEnd(z.src[z.pos]) && (z.pos == start || z.src[z.pos] != '=') {
+		z.pos++
+	}
+	return string(z.src[start:z.pos])
+}
+
+// attrValue reads quoted or unquoted attribute value
+func (z *htmlTokenizer) attrValue() string {
+	if z.pos >= len(z.src) {
+		return ""
+	}
+	var v []byte
+	if q := z.src[z.pos]; q == '"' || q == '\'' {
+		end := bytes.IndexByte(z.src[z.pos+1:], q)
+		if end < 0 {
+			end = len(z.src) - z.pos - 1
+		}
+		v = z.src[z.pos+1 : z.pos+1+end]
+		z.pos += end + 2
+	} else {
+		start := z.pos
+		for z.pos < len(z.src) && !isSpace(z.src[z.pos]) && z.src[z.pos] != '>' {
+			z.pos++
+		}
+		v = z.src[start:z.pos]
+	}
+	return html.UnescapeString(string(v))
+}
+
+func (z *htmlTokenizer) skipSpace() {
+	for z.pos < len(z.src) && isSpace(z.src[z.pos]) {
+		z.pos++
+	}
+}
+
+// isTagStart reports if s starts with tag, comment or directive
+func isTagStart(s []byte) bool {
+	if len(s) < 2 || s[0] != '<' {
+		return false
+	}
+	switch c := s[1]; {
+	case c == '!' || c == '?':
+		return true
+	case c == '/':
+		return len(s) > 2 && isLetter(s[2])
+	}
+	return isLetter(s[1])
+}
+
+func isNameEnd(c byte) bool {
+	return isSpace(c) || c == '/' || c == '>'
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f'
+}
+
+func isLetter(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+func copyBytes(b []byte) []byte {
+	return append([]byte(nil), b...)
+}
@@ -0,0 +1,229 @@
+package instant
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+)
+
+// Automatic ad placement densities
+const (
+	AdDensityDefault = "default"
+	AdDensityMedium  = "medium"
+	AdDensityLow     = "low"
+)
+
+// MarkupVersion is default instant articles markup version
+const MarkupVersion = "v1.0"
+
+// meta properties managed by ArticleOptions
+const (
+	metaMarkupVersion    = "op:markup_version"
+	metaStyle            = "fb:article_style"
+	metaAutomaticAds     = "fb:use_automatic_ad_placement"
+	metaRecirculationAds = "fb:op-recirculation-ads"
+)
+
+// ArticleOptions are article wide settings rendered as <meta> elements in article head.
+// Each option is rendered once and overrides meta element with the same property in Article.Head.Meta.
+// See https://developers.facebook.com/docs/instant-articles/reference for more info.
+type ArticleOptions struct {
+	Style            string // fb:article_style, name of style defined in Facebook Page settings
+	MarkupVersion    string // op:markup_version, MarkupVersion if empty
+	AutomaticAds     bool   // fb:use_automatic_ad_placement, let Facebook place ads in article
+	AdDensity        string // density of automatically placed ads, one of AdDensity... constants
+	RecirculationAds string // fb:op-recirculation-ads, ad placement ID for ads in related articles
+	LikesAndComments bool   // enable likes and comments on image and video figures without data-feedback
+}
+
+// SetOptions replaces article options.
+func (a *Article) SetOptions(o ArticleOptions) {
+	a.Options = o
+}
+
+// check returns error if options can't be rendered to valid meta elements
+func (o ArticleOptions) check() error {
+	switch o.AdDensity {
+	case "", AdDensityDefault, AdDensityMedium, AdDensityLow:
+	default:
+		return errors.New("Invalid ad density " + o.AdDensity)
+	}
+	if o.AdDensity != "" && !o.AutomaticAds {
+		return errors.New("Ad density requires automatic ad placement")
+	}
+	if o.RecirculationAds != "" && !isPlacementID(o.RecirculationAds) {
+		return errors.New("Invalid recirculation ads placement ID " + o.RecirculationAds)
+	}
+	if o.MarkupVersion != "" && o.MarkupVersion != MarkupVersion {
+		return errors.New("Unsupported markup version " + o.MarkupVersion)
+	}
+	return nil
+}
+
+// meta returns options as meta elements, markup version is not included
+func (o ArticleOptions) meta() []Meta {
+	var meta []Meta
+	if o.Style != "" {
+		meta = append(meta, Meta{Property: metaStyle, Content: o.Style})
+	}
+	if o.AutomaticAds {
+		content := "enable=true"
+		if o.AdDensity != "" {
+			content += " ad_density=" + o.AdDensity
+		}
+		meta = append(meta, Meta{Property: metaAutomaticAds, Content: content})
+	}
+	if o.RecirculationAds != "" {
+		meta = append(meta, Meta{Property: metaRecirculationAds, Content: "placement_id=" + o.RecirculationAds})
+	}
+	return meta
+}
+
+// feedback returns copy of content where image and video figures without data-feedback
+// have likes and comments enabled if LikesAndComments option is set
+func (o ArticleOptions) feedback(c Content) Content {
+	if !o.LikesAndComments {
+		return c
+	}
+	content := make(Content, len(c))
+	for i, t := range c {
+		switch f := t.(type) {
+		case Figure:
+			t = f.defaultFeedback()
+		case *Figure:
+			t = f.defaultFeedback()
+		}
+		content[i] = t
+	}
+	return content
+}
+
+// defaultFeedback returns figure with likes and comments enabled if it is image or video without data-feedback
+func (f Figure) defaultFeedback() Figure {
+	if f.Feedback == "" && f.Class == "" && (f.Img != nil || f.Video != nil) {
+		f.Feedback = feedback(true, true)
+	}
+	return f
+}
+
+// setMeta sets option from meta element, returns false if meta property is not an option
+func (o *ArticleOptions) setMeta(m Meta) bool {
+	switch m.Property {
+	case metaMarkupVersion:
+		o.MarkupVersion = m.Content
+	case metaStyle:
+		o.Style = m.Content
+	case metaAutomaticAds:
+		o.AutomaticAds = false
+		o.AdDensity = ""
+		for _, f := range strings.Fields(m.Content) {
+			switch {
+			case f == "true" || f == "enable=true":
+				o.AutomaticAds = true
+			case strings.HasPrefix(f, "ad_density="):
+				o.AdDensity = strings.TrimPrefix(f, "ad_density=")
+			}
+		}
+	case metaRecirculationAds:
+		o.RecirculationAds = strings.TrimPrefix(m.Content, "placement_id=")
+	default:
+		return false
+	}
+	return true
+}
+
+// options returns article options with markup version from head meta if MarkupVersion option is not set
+func (a *Article) options() ArticleOptions {
+	o := a.Options
+	if o.MarkupVersion != "" {
+		return o
+	}
+	// the last meta element wins, same as in headString
+	for _, m := range a.Head.Meta {
+		if m.Property == metaMarkupVersion {
+			o.MarkupVersion = m.Content
+		}
+	}
+	return o
+}
+
+// headString returns instant article head section with self-closing <meta/> and <link/> tags.
+// xml.Marshal by default doesn't produce self-closing tags.
+// Meta elements are rendered once, options override meta elements with the same property.
+func (a *Article) headString() (string, error) {
+	o := a.options()
+	if err := o.check(); err != nil {
+		return "", err
+	}
+
+	options := o.meta()
+	set := map[string]bool{}
+	for _, m := range options {
+		set[m.Property] = true
+	}
+
+	// deduplicate meta elements, the last one wins
+	charset := "utf-8"
+	var meta []Meta
+	index := map[string]int{}
+	for _, m := range a.Head.Meta {
+		switch {
+		case m.Charset != "":
+			charset = m.Charset
+		case m.Property == metaMarkupVersion, set[m.Property]:
+		default:
+			if i, ok := index[m.Property]; ok {
+				meta[i] = m
+				continue
+			}
+			index[m.Property] = len(meta)
+			meta = append(meta, m)
+		}
+	}
+	markupVersion := MarkupVersion
+	if o.MarkupVersion != "" {
+		markupVersion = o.MarkupVersion
+	}
+
+	var buff bytes.Buffer
+
+	// link element
+	buff.WriteString("\n<link href=\"")
+	attrEscaper.WriteString(&buff, a.Head.Link.Href)
+	buff.WriteString("\" rel=\"")
+	attrEscaper.WriteString(&buff, a.Head.Link.Rel)
+	buff.WriteString("\" />")
+
+	// meta elements
+	writeMeta(&buff, Meta{Charset: charset})
+	writeMeta(&buff, Meta{Property: metaMarkupVersion, Content: markupVersion})
+	for _, m := range options {
+		writeMeta(&buff, m)
+	}
+	for _, m := range meta {
+		writeMeta(&buff, m)
+	}
+
+	return buff.String(), nil
+}
+
+// writeMeta writes self-closing meta element
+func writeMeta(buff *bytes.Buffer, m Meta) {
+	buff.WriteString("\n<meta ")
+	if m.Charset != "" {
+		buff.WriteString("charset=\"")
+		attrEscaper.WriteString(buff, m.Charset)
+		buff.WriteString("\" ")
+	}
+	if m.Property != "" {
+		buff.WriteString("property=\"")
+		attrEscaper.WriteString(buff, m.Property)
+		buff.WriteString("\" ")
+	}
+	if m.Content != "" {
+		buff.WriteString("content=\"")
+		attrEscaper.WriteString(buff, m.Content)
+		buff.WriteString("\" ")
+	}
+	buff.WriteString("/>")
+}
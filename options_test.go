@@ -0,0 +1,96 @@
+package instant_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mileusna/facebook-instant-articles"
+)
+
+func TestOptions(t *testing.T) {
+	var a instant.Article
+	a.SetTitle("My article title")
+	a.SetCanonical("http://mysite/url-to-this-article")
+	a.Head.Meta = append(a.Head.Meta,
+		instant.Meta{Property: "fb:article_style", Content: "overridden"},
+		instant.Meta{Property: "custom", Content: "1"},
+		instant.Meta{Property: "custom", Content: "2"},
+	)
+	a.SetOptions(instant.ArticleOptions{
+		Style:            "mystyle",
+		AutomaticAds:     true,
+		AdDensity:        instant.AdDensityLow,
+		LikesAndComments: true,
+	})
+	a.SetCoverImage("http://mysite/cover.jpg", "")
+	a.AddFigure(instant.NewImageFigure("http://mysite/img.jpg", "", instant.ImageOptions{Likes: true}))
+	a.AddFigure(instant.Figure{Class: "op-ad", IFrame: &instant.IFrame{Src: "http://mysite/ad", Width: "300", Height: "250"}})
+	if err := a.SetAutomaticAd("http://mysite/ad", 300, 250, "", ""); err == nil {
+		t.Error("expected error for automatic ad in article with ads in content")
+	}
+
+	// ad in content can't be mixed with automatic ad placement
+	mixed := false
+	for _, d := range a.Validate() {
+		mixed = mixed || d.Rule == instant.RuleAdMixed
+	}
+	if !mixed {
+		t.Errorf("expected %s diagnostic", instant.RuleAdMixed)
+	}
+	if _, err := a.HTML(); err == nil {
+		t.Error("expected error for ad in content of article with automatic ad placement")
+	}
+	a.Body.Article.Content = a.Body.Article.Content[:1]
+
+	html, err := a.HTML()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{
+		`<head>
+<link href="http://mysite/url-to-this-article" rel="canonical" />
+<meta charset="utf-8" />
+<meta property="op:markup_version" content="v1.0" />
+<meta property="fb:article_style" content="mystyle" />
+<meta property="fb:use_automatic_ad_placement" content="enable=true ad_density=low" />
+<meta property="custom" content="2" /></head>`,
+		`<figure data-feedback="fb:likes,fb:comments"><img src="http://mysite/cover.jpg"></img></figure>`,
+		`<figure data-feedback="fb:likes"><img src="http://mysite/img.jpg"></img></figure>`,
+	} {
+		if !strings.Contains(string(html), s) {
+			t.Errorf("%s not found in %s", s, html)
+		}
+	}
+
+	b, err := instant.ParseArticle(strings.NewReader(string(html)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := a.Options
+	expected.MarkupVersion = instant.MarkupVersion
+	expected.LikesAndComments = false // rendered as data-feedback of figures
+	if b.Options != expected {
+		t.Errorf("expected options %#v, got %#v", expected, b.Options)
+	}
+
+	// markup version set in head meta is checked too
+	a.Head.Meta = append(a.Head.Meta, instant.Meta{Property: "op:markup_version", Content: "v9"})
+	if _, err := a.HTML(); err == nil {
+		t.Error("expected error for unsupported markup version in head meta")
+	}
+	if ds := a.Validate(); len(ds) == 0 || ds[0].Rule != instant.RuleOptionsInvalid {
+		t.Errorf("expected options diagnostic, got %v", ds)
+	}
+	a.Options.MarkupVersion = instant.MarkupVersion
+	if _, err := a.HTML(); err != nil {
+		t.Error(err)
+	}
+	a.Options.MarkupVersion = ""
+	a.Head.Meta = a.Head.Meta[:len(a.Head.Meta)-1]
+
+	a.Options.AutomaticAds = false
+	a.Options.AdDensity = instant.AdDensityMedium
+	if _, err := a.HTML(); err == nil {
+		t.Error("expected error for ad density without automatic ads")
+	}
+}
@@ -0,0 +1,581 @@
+package instant
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+	"strings"
+)
+
+// Dropped describes part of the source HTML which couldn't be mapped to any
+// ContentTag and was left out of article content.
+type Dropped struct {
+	Tag    string // element name, empty for text
+	Offset int64  // byte offset in source HTML
+	HTML   string // dropped markup
+	Reason string
+}
+
+var (
+	textEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+	attrEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", "\"", "&quot;")
+)
+
+// elements which are walked through, their children are parsed as content
+var containerTags = map[string]bool{
+	"html": true, "body": true, "main": true, "article": true, "section": true, "div": true,
+}
+
+// elements which are joined with surrounding text into paragraph
+var inlineTags = map[string]bool{
+	"a": true, "abbr": true, "b": true, "bdi": true, "bdo": true, "br": true, "cite": true,
+	"code": true, "data": true, "del": true, "dfn": true, "em": true, "i": true, "ins": true,
+	"kbd": true, "mark": true, "q": true, "s": true, "samp": true, "small": true, "span": true,
+	"strong": true, "sub": true, "sup": true, "time": true, "u": true, "var": true, "wbr": true,
+}
+
+// ParseContent parses HTML fragment or document and maps every recognized block element
+// to ContentTag. Bare text and inline elements between blocks are joined into paragraphs.
+// Elements that can't be represented in instant article are returned as dropped.
+// If HTML can't be parsed, content parsed so far is returned along with error and
+// the rest of HTML is returned as dropped with error as reason.
+func ParseContent(html string) (Content, []Dropped, error) {
+	z := newHTMLTokenizer(strings.NewReader(html))
+	p := contentParser{d: xml.NewTokenDecoder(z), z: z}
+	err := p.parse()
+	if err != nil {
+		p.flush()
+		p.dropped = append(p.dropped, Dropped{
+			Tag:    p.errTag,
+			Offset: p.errOffset,
+			HTML:   html[p.errOffset:],
+			Reason: err.Error(),
+		})
+	}
+	return p.content, p.dropped, err
+}
+
+// contentParser walks HTML tokens and collects content tags
+type contentParser struct {
+	d         *xml.Decoder
+	z         *htmlTokenizer // source of decoder tokens, nil if offsets are unknown
+	content   Content
+	dropped   []Dropped
+	inline    bytes.Buffer // text and inline elements not yet added as paragraph
+	errTag    string       // innermost element which couldn't be parsed
+	errOffset int64
+}
+
+// parse reads all tokens until the end of input
+func (p *contentParser) parse() error {
+	for {
+		t, err := p.d.Token()
+		if err == io.EOF {
+			p.flush()
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := p.token(t); err != nil {
+			return err
+		}
+	}
+}
+
+// children parses tokens until end element of current element
+func (p *contentParser) children() error {
+	if err := walkTokens(p.d, p.token); err != nil {
+		return err
+	}
+	p.flush()
+	return nil
+}
+
+// token handles single token on the content level
+func (p *contentParser) token(t xml.Token) error {
+	switch t := t.(type) {
+	case xml.StartElement:
+		offset := p.offset()
+		err := p.element(t, offset)
+		if err != nil && p.errTag == "" {
+			p.errTag = strings.ToLower(t.Name.Local)
+			p.errOffset = offset
+		}
+		return err
+	case xml.CharData:
+		textEscaper.WriteString(&p.inline, string(t))
+	}
+	// comments, directives and processing instructions are ignored
+	return nil
+}
+
+// element maps element to content tag. Entire element is consumed from decoder.
+func (p *contentParser) element(start xml.StartElement, offset int64) error {
+	name := strings.ToLower(start.Name.Local)
+	switch {
+	case inlineTags[name]:
+		return writeElement(p.d, &p.inline, start)
+
+	case containerTags[name]:
+		p.flush()
+		return p.children()
+
+	case name == "p":
+		p.flush()
+		s, err := innerHTML(p.d)
+		if err != nil {
+			return err
+		}
+		if strings.TrimSpace(s) == "" {
+			p.drop(name, offset, "<p></p>", "empty paragraph")
+			return nil
+		}
+		p.content = append(p.content, P{Text: strings.TrimSpace(s)})
+		return nil
+
+	case len(name) == 2 && name[0] == 'h' && name[1] >= '1' && name[1] <= '6':
+		p.flush()
+		s, err := innerHTML(p.d)
+		if err != nil {
+			return err
+		}
+		if s = strings.TrimSpace(s); s == "" {
+			p.drop(name, offset, "<"+name+"></"+name+">", "empty heading")
+			return nil
+		}
+		p.content = append(p.content, Heading{Level: headingLevel(int(name[1] - '0')), Text: s})
+		return nil
+
+	case name == "blockquote":
+		p.flush()
+		s, err := innerHTML(p.d)
+		if err != nil {
+			return err
+		}
+		if s = strings.TrimSpace(s); s == "" {
+			p.drop(name, offset, "<blockquote></blockquote>", "empty quote")
+			return nil
+		}
+		p.content = append(p.content, Blockquote{Text: s})
+		return nil
+
+	case name == "aside":
+		p.flush()
+		q, err := p.pullQuote()
+		if err != nil {
+			return err
+		}
+		if q.Text == "" {
+			p.drop(name, offset, "<aside></aside>", "empty quote")
+			return nil
+		}
+		p.content = append(p.content, q)
+		return nil
+
+	case name == "ul" && hasClass(start, "op-related-articles"):
+		p.flush()
+		var r RelatedArticles
+		if err := p.d.DecodeElement(&r, &start); err != nil {
+			return err
+		}
+		p.content = append(p.content, r)
+		return nil
+
+	case name == "ul" || name == "ol":
+		p.flush()
+		items, err := p.listItems()
+		if err != nil {
+			return err
+		}
+		if len(items) == 0 {
+			p.drop(name, offset, "<"+name+"></"+name+">", "empty list")
+			return nil
+		}
+		if name == "ul" {
+			p.content = append(p.content, UL{LI: items})
+		} else {
+			p.content = append(p.content, OL{LI: items})
+		}
+		return nil
+
+	case name == "figure" && hasClass(start, "op-map"):
+		p.flush()
+		var m Map
+		if err := p.d.DecodeElement(&m, &start); err != nil {
+			return err
+		}
+		p.content = append(p.content, m)
+		return nil
+
+	case name == "figure" && hasClass(start, "op-slideshow"):
+		p.flush()
+		var s Slideshow
+		if err := p.d.DecodeElement(&s, &start); err != nil {
+			return err
+		}
+		p.content = append(p.content, s)
+		return nil
+
+	case name == "figure":
+		p.flush()
+		var f Figure
+		if err := p.d.DecodeElement(&f, &start); err != nil {
+			return err
+		}
+		p.content = append(p.content, f)
+		return nil
+	}
+
+	p.flush()
+	var buff bytes.Buffer
+	if err := writeElement(p.d, &buff, start); err != nil {
+		return err
+	}
+	p.drop(name, offset, buff.String(), "unsupported element")
+	return nil
+}
+
+// listItems reads <li> elements of list, other elements are dropped
+func (p *contentParser) listItems() ([]LI, error) {
+	var items []LI
+	err := walk(p.d, func(t xml.StartElement) error {
+		offset := p.offset()
+		name := strings.ToLower(t.Name.Local)
+		if name != "li" {
+			var buff bytes.Buffer
+			if err := writeElement(p.d, &buff, t); err != nil {
+				return err
+			}
+			p.drop(name, offset, buff.String(), "unsupported list element")
+			return nil
+		}
+		s, err := innerHTML(p.d)
+		if err != nil {
+			return err
+		}
+		if s = strings.TrimSpace(s); s != "" {
+			items = append(items, LI{Text: s})
+		}
+		return nil
+	})
+	return items, err
+}
+
+// pullQuote reads content of <aside> with optional <cite> attribution
+func (p *contentParser) pullQuote() (PullQuote, error) {
+	var q PullQuote
+	var buff bytes.Buffer
+	err := walkTokens(p.d, func(t xml.Token) error {
+		switch t := t.(type) {
+		case xml.StartElement:
+			if strings.ToLower(t.Name.Local) == "cite" {
+				return decodeText(p.d, &t, &q.Cite)
+			}
+			return writeElement(p.d, &buff, t)
+		default:
+			writeToken(&buff, t)
+		}
+		return nil
+	})
+	q.Text = strings.TrimSpace(buff.String())
+	return q, err
+}
+
+// flush adds pending text and inline elements to content as paragraph
+func (p *contentParser) flush() {
+	if s := strings.TrimSpace(p.inline.String()); s != "" {
+		p.content = append(p.content, P{Text: s})
+	}
+	p.inline.Reset()
+}
+
+// drop records element which was left out of content
+func (p *contentParser) drop(tag string, offset int64, html, reason string) {
+	p.dropped = append(p.dropped, Dropped{
+		Tag:    tag,
+		Offset: offset,
+		HTML:   html,
+		Reason: reason,
+	})
+}
+
+// offset returns source offset of last token read
+func (p *contentParser) offset() int64 {
+	if p.z == nil {
+		return 0
+	}
+	return p.z.offset
+}
+
+// innerHTML returns markup between already consumed start element and its end element.
+func innerHTML(d *xml.Decoder) (string, error) {
+	var buff bytes.Buffer
+	if err := writeChildren(d, &buff, false); err != nil {
+		return "", err
+	}
+	return buff.String(), nil
+}
+
+// writeElement writes already consumed start element, its children and end element to buff.
+func writeElement(d *xml.Decoder, buff *bytes.Buffer, start xml.StartElement) error {
+	writeToken(buff, start)
+	if err := writeChildren(d, buff, rawTextTags[strings.ToLower(start.Name.Local)]); err != nil {
+		return err
+	}
+	writeToken(buff, start.End())
+	return nil
+}
+
+// writeChildren writes tokens to buff until end element of current element.
+// Text is written unescaped if raw is true, i.e. if current element is script or style.
+func writeChildren(d *xml.Decoder, buff *bytes.Buffer, raw bool) error {
+	for depth := 0; ; {
+		t, err := d.Token()
+		if err != nil {
+			return err
+		}
+		switch t := t.(type) {
+		case xml.StartElement:
+			depth++
+			raw = rawTextTags[strings.ToLower(t.Name.Local)]
+		case xml.EndElement:
+			if depth == 0 {
+				return nil
+			}
+			depth--
+			raw = false
+		case xml.CharData:
+			if raw {
+				buff.Write(t)
+				continue
+			}
+		}
+		writeToken(buff, t)
+	}
+}
+
+// writeToken writes token as HTML. Void elements like <br> are written self-closed.
+func writeToken(buff *bytes.Buffer, t xml.Token) {
+	switch t := t.(type) {
+	case xml.StartElement:
+		buff.WriteString("<")
+		buff.WriteString(tagName(t.Name))
+		for _, a := range t.Attr {
+			buff.WriteString(" ")
+			if a.Name.Space == bareAttr {
+				buff.WriteString(a.Name.Local)
+				continue
+			}
+			buff.WriteString(tagName(a.Name))
+			buff.WriteString("=\"")
+			attrEscaper.WriteString(buff, a.Value)
+			buff.WriteString("\"")
+		}
+		if isVoid(t.Name.Local) {
+			buff.WriteString(" />")
+		} else {
+			buff.WriteString(">")
+		}
+	case xml.EndElement:
+		if !isVoid(t.Name.Local) {
+			buff.WriteString("</")
+			buff.WriteString(tagName(t.Name))
+			buff.WriteString(">")
+		}
+	case xml.CharData:
+		textEscaper.WriteString(buff, string(t))
+	case xml.Comment:
+		buff.WriteString("<!--")
+		buff.Write(t)
+		buff.WriteString("-->")
+	}
+}
+
+// decodeText decodes text content of element to s
+func decodeText(d *xml.Decoder, start *xml.StartElement, s *string) error {
+	v := struct {
+		Text string `xml:",chardata"`
+	}{}
+	err := d.DecodeElement(&v, start)
+	*s = strings.TrimSpace(v.Text)
+	return err
+}
+
+// hasClass reports if element has class in its class attribute
+func hasClass(start xml.StartElement, class string) bool {
+	for _, attr := range start.Attr {
+		if attr.Name.Local == "class" {
+			for _, c := range strings.Fields(attr.Value) {
+				if c == class {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// tagName returns element or attribute name with prefix
+func tagName(n xml.Name) string {
+	if n.Space != "" {
+		return n.Space + ":" + n.Local
+	}
+	return n.Local
+}
+
+// isVoid reports if element is HTML void element which has no end tag
+func isVoid(name string) bool {
+	return voidTags[strings.ToLower(name)]
+}
+
+// ParseArticle reads Facebook instant article HTML document and reconstructs Article.
+// HTML doesn't have to be strict XML, unclosed elements, HTML entities and scripts are supported.
+func ParseArticle(r io.Reader) (Article, error) {
+	var a Article
+	err := newHTMLDecoder(r).Decode(&a)
+	return a, err
+}
+
+// UnmarshalXML for xml.Unmarshaler interface, unmarshal Facebook Instant Article html to Article struct.
+func (a *Article) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	if !strings.EqualFold(start.Name.Local, "html") {
+		return errors.New("Article root element <html> is required")
+	}
+	for _, attr := range start.Attr {
+		switch attr.Name.Local {
+		case "lang":
+			a.Lang = attr.Value
+		case "prefix":
+			a.Prefix = attr.Value
+		}
+	}
+
+	return walk(d, func(t xml.StartElement) error {
+		switch strings.ToLower(t.Name.Local) {
+		case "head":
+			return a.unmarshalHead(d)
+		case "body":
+			return walk(d, func(t xml.StartElement) error {
+				if strings.ToLower(t.Name.Local) == "article" {
+					return a.unmarshalArticle(d)
+				}
+				return d.Skip()
+			})
+		}
+		return d.Skip()
+	})
+}
+
+// unmarshalHead reads canonical link and meta elements from <head>
+func (a *Article) unmarshalHead(d *xml.Decoder) error {
+	return walk(d, func(t xml.StartElement) error {
+		switch strings.ToLower(t.Name.Local) {
+		case "link":
+			var l link
+			if err := d.DecodeElement(&l, &t); err != nil {
+				return err
+			}
+			if l.Rel == "canonical" {
+				a.Head.Link = l
+			}
+			return nil
+		case "meta":
+			var m Meta
+			if err := d.DecodeElement(&m, &t); err != nil {
+				return err
+			}
+			if !a.Options.setMeta(m) {
+				a.Head.Meta = append(a.Head.Meta, m)
+			}
+			return nil
+		}
+		return d.Skip()
+	})
+}
+
+// unmarshalArticle reads header, footer and content elements from <article>
+func (a *Article) unmarshalArticle(d *xml.Decoder) error {
+	p := contentParser{d: d}
+	err := walkTokens(d, func(t xml.Token) error {
+		if start, ok := t.(xml.StartElement); ok {
+			switch strings.ToLower(start.Name.Local) {
+			case "header":
+				p.flush()
+				return d.DecodeElement(&a.Body.Article.Header, &start)
+			case "footer":
+				p.flush()
+				return d.DecodeElement(&a.Body.Article.Footer, &start)
+			}
+		}
+		return p.token(t)
+	})
+	if err != nil {
+		return err
+	}
+	p.flush()
+	a.Body.Article.Content = append(a.Body.Article.Content, p.content...)
+	return nil
+}
+
+// UnmarshalXML for xml.Unmarshaler interface, appends single content element to content.
+func (c *Content) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	p := contentParser{d: d}
+	if err := p.element(start, p.offset()); err != nil {
+		return err
+	}
+	*c = append(*c, p.content...)
+	return nil
+}
+
+// UnmarshalXML for xml.Unmarshaler interface, iframe content is read as HTML
+func (f *IFrame) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	for _, attr := range start.Attr {
+		switch attr.Name.Local {
+		case "src":
+			f.Src = attr.Value
+		case "height":
+			f.Height = attr.Value
+		case "width":
+			f.Width = attr.Value
+		case "style":
+			f.Style = attr.Value
+		case "hidden":
+			f.Hidden = attr.Value
+		case "class":
+			f.Class = attr.Value
+		}
+	}
+	s, err := innerHTML(d)
+	f.Text = s
+	return err
+}
+
+// walk calls fn for every child element of current element until its end element.
+// fn must consume entire element.
+func walk(d *xml.Decoder, fn func(t xml.StartElement) error) error {
+	return walkTokens(d, func(t xml.Token) error {
+		if start, ok := t.(xml.StartElement); ok {
+			return fn(start)
+		}
+		return nil
+	})
+}
+
+// walkTokens calls fn for every child token of current element until its end element.
+// If token is start element, fn must consume entire element.
+func walkTokens(d *xml.Decoder, fn func(t xml.Token) error) error {
+	for {
+		t, err := d.Token()
+		if err != nil {
+			return err
+		}
+		if _, ok := t.(xml.EndElement); ok {
+			return nil
+		}
+		if err := fn(t); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,187 @@
+package instant_test
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/mileusna/facebook-instant-articles"
+)
+
+func TestParseContent(t *testing.T) {
+	html := `<div class="body">Intro <b>text</b>
+		<p class="lead">Plain &amp; simple<br>text</p>
+		<figure><img src="http://mysite/img.jpg"/><figcaption>Caption</figcaption></figure>
+		<script>alert(1)</script>
+		<p></p>
+		<p>Unclosed`
+
+	c, dropped, err := instant.ParseContent(html)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(c) != 4 {
+		t.Fatalf("expected 4 content tags, got %d", len(c))
+	}
+	if p, ok := c[0].(instant.P); !ok || p.Text != "Intro <b>text</b>" {
+		t.Errorf("unexpected first paragraph %#v", c[0])
+	}
+	if p, ok := c[1].(instant.P); !ok || p.Text != "Plain &amp; simple<br />text" {
+		t.Errorf("unexpected second paragraph %#v", c[1])
+	}
+	if f, ok := c[2].(instant.Figure); !ok || f.Img == nil || f.Img.Src != "http://mysite/img.jpg" || f.Figcaption == nil || f.Figcaption.Text != "Caption" {
+		t.Errorf("unexpected figure %#v", c[2])
+	}
+	if p, ok := c[3].(instant.P); !ok || p.Text != "Unclosed" {
+		t.Errorf("unexpected last paragraph %#v", c[3])
+	}
+
+	if len(dropped) != 2 {
+		t.Fatalf("expected 2 dropped elements, got %d", len(dropped))
+	}
+	if dropped[0].Tag != "script" || dropped[0].HTML != "<script>alert(1)</script>" {
+		t.Errorf("unexpected dropped element %#v", dropped[0])
+	}
+	if dropped[1].Tag != "p" {
+		t.Errorf("unexpected dropped element %#v", dropped[1])
+	}
+}
+
+func TestParseArticle(t *testing.T) {
+	a := instant.Article{}
+	a.SetTitle("My article title")
+	a.SetCanonical("http://mysite/url-to-this-article")
+	a.SetSubtitle("My article subtitle")
+	a.SetKick("Exclusive!")
+	a.SetLang("fr")
+	a.SetStyle("default")
+	a.SetPublish(time.Date(2016, 11, 11, 4, 44, 16, 0, time.UTC))
+	a.SetCoverImage("http://mysite/cover.jpg", "Cover")
+	a.SetFooter("Credits", "©MyComp 2016")
+	a.AddAuthor("Michael", "http://facebook.com/mmichael", "Guest writter")
+	a.SetContent("<p>Plain <b>text</b></p><figure><img src=\"http://mysite/img.jpg\"/></figure><p>Other paragraph</p>")
+	a.SetTrackerURL("http://mysite/tracker")
+
+	html, err := a.HTML()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := instant.ParseArticle(bytes.NewReader(html))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if b.Lang != "fr" || b.Head.Link.Href != "http://mysite/url-to-this-article" {
+		t.Errorf("unexpected lang %q or canonical link %q", b.Lang, b.Head.Link.Href)
+	}
+	if h := b.Body.Article.Header; h.H1 != "My article title" || h.H2 != "My article subtitle" || h.H3 == nil || len(h.Time) != 1 || len(h.Address) != 1 || len(h.Figure) != 1 {
+		t.Errorf("unexpected header %#v", h)
+	}
+	if len(b.Body.Article.Content) != 4 {
+		t.Errorf("expected 4 content tags, got %d", len(b.Body.Article.Content))
+	}
+
+	html2, err := b.HTML()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(html, html2) {
+		t.Errorf("round trip mismatch\n%s\n%s", html, html2)
+	}
+}
+
+func TestParseContentImpliedEnd(t *testing.T) {
+	html := `<p>one<p>two</p></div><ul><li>x<li>y</ul><script>if (a < b && b > c) {}</script><p>end`
+
+	c, dropped, err := instant.ParseContent(html)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(c) != 4 {
+		t.Fatalf("expected 4 content tags, got %#v", c)
+	}
+	for i, s := range []string{"one", "two"} {
+		if p, ok := c[i].(instant.P); !ok || p.Text != s {
+			t.Errorf("unexpected paragraph %#v", c[i])
+		}
+	}
+	if ul, ok := c[2].(instant.UL); !ok || len(ul.LI) != 2 || ul.LI[0].Text != "x" || ul.LI[1].Text != "y" {
+		t.Errorf("unexpected list %#v", c[2])
+	}
+	if p, ok := c[3].(instant.P); !ok || p.Text != "end" {
+		t.Errorf("unexpected paragraph %#v", c[3])
+	}
+
+	if len(dropped) != 1 {
+		t.Fatalf("expected 1 dropped element, got %#v", dropped)
+	}
+	if d := dropped[0]; d.Tag != "script" || d.HTML != "<script>if (a < b && b > c) {}</script>" || html[d.Offset:d.Offset+7] != "<script" {
+		t.Errorf("unexpected dropped element %#v", d)
+	}
+}
+
+func TestParseContentError(t *testing.T) {
+	html := `<p>intro</p><figure class="op-map"><script type="application/json" class="op-geotag">not json</script></figure><p>outro</p>`
+
+	c, dropped, err := instant.ParseContent(html)
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	if len(c) != 1 {
+		t.Fatalf("expected 1 content tag, got %#v", c)
+	}
+	if len(dropped) != 1 || dropped[0].Tag != "figure" || dropped[0].HTML != html[len("<p>intro</p>"):] || dropped[0].Reason != err.Error() {
+		t.Errorf("unexpected dropped %#v", dropped)
+	}
+
+	var a instant.Article
+	if dropped := a.SetContent(html); len(dropped) != 1 {
+		t.Errorf("expected 1 dropped, got %#v", dropped)
+	}
+	if len(a.Body.Article.Content) != 1 {
+		t.Errorf("expected 1 content tag, got %#v", a.Body.Article.Content)
+	}
+}
+
+func TestParseArticleScripts(t *testing.T) {
+	a := instant.Article{}
+	a.SetTitle("My article title")
+	a.SetCanonical("http://mysite/url-to-this-article")
+	a.SetPublish(time.Date(2016, 11, 11, 4, 44, 16, 0, time.UTC))
+	a.AddParagraph("Plain text")
+	a.SetTrackerCode("<script>for(var i=0;i<3;i++){}</script>")
+	if err := a.AddInteractive(instant.Interactive{HTML: `<div id="chart"></div><script>if (a < b && b > c) draw("chart")</script>`}); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.AddTracker(instant.GA4Tracker{MeasurementID: "G-12345"}); err != nil {
+		t.Fatal(err)
+	}
+
+	html, err := a.HTML()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(html, []byte(`<script async src="https://www.googletagmanager.com/gtag/js?id=G-12345">`)) {
+		t.Errorf("async script not found in %s", html)
+	}
+	b, err := instant.ParseArticle(bytes.NewReader(html))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(b.Body.Article.Content) != 4 {
+		t.Fatalf("expected 4 content tags, got %#v", b.Body.Article.Content)
+	}
+	if f, ok := b.Body.Article.Content[1].(instant.Figure); !ok || f.IFrame == nil || f.IFrame.Text != "<script>for(var i=0;i<3;i++){}</script>" {
+		t.Errorf("unexpected tracker %#v", b.Body.Article.Content[1])
+	}
+
+	html2, err := b.HTML()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(html, html2) {
+		t.Errorf("round trip mismatch\n%s\n%s", html, html2)
+	}
+}
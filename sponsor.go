@@ -0,0 +1,81 @@
+package instant
+
+import (
+	"encoding/xml"
+	"net/url"
+	"strings"
+)
+
+// Sponsors is <ul class="op-sponsors"> list of Facebook Pages sponsoring branded content.
+// See https://developers.facebook.com/docs/instant-articles/reference/branded-content for more info.
+type Sponsors struct {
+	Pages []string // Facebook Page URLs
+}
+
+// AddSponsor adds Facebook Page URL of branded content sponsor to article header.
+func (a *Article) AddSponsor(pageURL string) {
+	h := &a.Body.Article.Header
+	if h.Sponsors == nil {
+		h.Sponsors = &Sponsors{}
+	}
+	h.Sponsors.Pages = append(h.Sponsors.Pages, pageURL)
+}
+
+// SetRecirculationAds enables Audience Network ads in related articles
+// shown below the article, using ad placement ID.
+func (a *Article) SetRecirculationAds(placementID string) {
+	a.Options.RecirculationAds = placementID
+}
+
+// MarshalXML for xml.Marshaler interface
+func (s Sponsors) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	start.Name.Local = "ul"
+	start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "class"}, Value: "op-sponsors"})
+
+	type a struct {
+		Href string `xml:"href,attr"`
+		Rel  string `xml:"rel,attr"`
+	}
+	type li struct {
+		A a `xml:"a"`
+	}
+	v := struct {
+		Li []li `xml:"li"`
+	}{}
+	for _, p := range s.Pages {
+		v.Li = append(v.Li, li{A: a{Href: p, Rel: "facebook"}})
+	}
+	return e.EncodeElement(v, start)
+}
+
+// UnmarshalXML for xml.Unmarshaler interface
+func (s *Sponsors) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	v := struct {
+		Li []struct {
+			A struct {
+				Href string `xml:"href,attr"`
+			} `xml:"a"`
+		} `xml:"li"`
+	}{}
+	if err := d.DecodeElement(&v, &start); err != nil {
+		return err
+	}
+	for _, li := range v.Li {
+		s.Pages = append(s.Pages, li.A.Href)
+	}
+	return nil
+}
+
+// isFacebookPage reports if s is URL of Facebook Page
+func isFacebookPage(s string) bool {
+	u, err := url.Parse(s)
+	if err != nil || u.Scheme != "http" && u.Scheme != "https" {
+		return false
+	}
+	switch strings.ToLower(u.Hostname()) {
+	case "facebook.com", "www.facebook.com", "m.facebook.com", "fb.com", "www.fb.com":
+	default:
+		return false
+	}
+	return len(pathSegments(u)) > 0
+}
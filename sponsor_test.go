@@ -0,0 +1,69 @@
+package instant_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mileusna/facebook-instant-articles"
+)
+
+func TestSponsor(t *testing.T) {
+	var a instant.Article
+	a.SetTitle("My article title")
+	a.SetCanonical("http://mysite/url-to-this-article")
+	a.SetPublish(time.Now())
+	a.AddSponsor("https://www.facebook.com/mysponsor")
+	a.SetRecirculationAds("141956036215488_141956099548815")
+
+	if ds := a.Validate(); len(ds) != 0 {
+		t.Errorf("unexpected diagnostics %v", ds)
+	}
+
+	html, err := a.HTML()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{
+		`<meta property="fb:op-recirculation-ads" content="placement_id=141956036215488_141956099548815" />`,
+		`<ul class="op-sponsors"><li><a href="https://www.facebook.com/mysponsor" rel="facebook"></a></li></ul></header>`,
+	} {
+		if !strings.Contains(string(html), s) {
+			t.Errorf("%s not found in %s", s, html)
+		}
+	}
+
+	b, err := instant.ParseArticle(strings.NewReader(string(html)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := b.Body.Article.Header.Sponsors; s == nil || len(s.Pages) != 1 {
+		t.Errorf("unexpected sponsors %#v", s)
+	}
+
+	// every sponsor has its own list item
+	a.AddSponsor("https://www.facebook.com/othersponsor")
+	html, err = a.HTML()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ul := `<ul class="op-sponsors">` +
+		`<li><a href="https://www.facebook.com/mysponsor" rel="facebook"></a></li>` +
+		`<li><a href="https://www.facebook.com/othersponsor" rel="facebook"></a></li></ul>`
+	if !strings.Contains(string(html), ul) {
+		t.Errorf("%s not found in %s", ul, html)
+	}
+	b, err = instant.ParseArticle(strings.NewReader(string(html)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := b.Body.Article.Header.Sponsors; s == nil || len(s.Pages) != 2 || s.Pages[1] != "https://www.facebook.com/othersponsor" {
+		t.Errorf("unexpected sponsors %#v", s)
+	}
+
+	a.AddSponsor("https://mysponsor.com")
+	ds := a.Validate()
+	if len(ds) != 1 || ds[0].Rule != instant.RuleSponsorURL {
+		t.Errorf("expected sponsor diagnostic, got %v", ds)
+	}
+}
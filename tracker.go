@@ -0,0 +1,153 @@
+package instant
+
+import (
+	"encoding/json"
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Tracker generates analytics code for op-tracker figure.
+// Generated code reads article url and referrer from ia_document environment variables
+// and falls back to canonical url and title of article.
+// See https://developers.facebook.com/docs/instant-articles/reference/analytics for more info.
+type Tracker interface {
+	// TrackerCode returns html code with script which reports page view
+	TrackerCode(canonical, title string) (string, error)
+}
+
+// AddTracker adds analytics tracker to article content.
+// Title and canonical url should be set before tracker is added.
+func (a *Article) AddTracker(t Tracker) error {
+	code, err := t.TrackerCode(a.Head.Link.Href, a.Body.Article.Header.H1)
+	if err != nil {
+		return err
+	}
+	a.SetTrackerCode(code)
+	return nil
+}
+
+var (
+	matchGA4ID  = regexp.MustCompile(`^G-[A-Z0-9]+$`)
+	matchDigits = regexp.MustCompile(`^[0-9]+$`)
+	matchAPIKey = regexp.MustCompile(`^[a-zA-Z0-9.-]+$`)
+)
+
+// GA4Tracker is Google Analytics 4 tracker
+type GA4Tracker struct {
+	MeasurementID string // e.g. G-XXXXXXXXXX
+}
+
+// TrackerCode for Tracker interface
+func (t GA4Tracker) TrackerCode(canonical, title string) (string, error) {
+	if !matchGA4ID.MatchString(t.MeasurementID) {
+		return "", errors.New("Invalid Google Analytics measurement ID " + t.MeasurementID)
+	}
+	return `<script async src="https://www.googletagmanager.com/gtag/js?id=` + t.MeasurementID + `"></script>` +
+		`<script>` + iaDocument(canonical, title) +
+		`window.dataLayer = window.dataLayer || [];` +
+		`function gtag(){dataLayer.push(arguments);}` +
+		`gtag('js', new Date());` +
+		`gtag('config', ` + jsString(t.MeasurementID) + `, {` +
+		`page_location: iaURL, page_referrer: iaReferrer, page_title: iaTitle, ` +
+		`campaign_source: 'Facebook', campaign_medium: 'Instant Articles'});` +
+		`</script>`, nil
+}
+
+// ComScoreTracker is comScore tracker
+type ComScoreTracker struct {
+	ClientID string // comScore c2 value
+}
+
+// TrackerCode for Tracker interface
+func (t ComScoreTracker) TrackerCode(canonical, title string) (string, error) {
+	if !matchDigits.MatchString(t.ClientID) {
+		return "", errors.New("Invalid comScore client ID " + t.ClientID)
+	}
+	return `<script>` + iaDocument(canonical, title) +
+		`var _comscore = _comscore || [];` +
+		`_comscore.push({c1: "2", c2: ` + jsString(t.ClientID) + `, c7: iaURL, c8: iaTitle, c9: iaReferrer});` +
+		`</script>` +
+		`<script async src="https://sb.scorecardresearch.com/beacon.js"></script>`, nil
+}
+
+// ChartbeatTracker is Chartbeat tracker
+type ChartbeatTracker struct {
+	UID      int    // Chartbeat account ID
+	Domain   string // site domain registered with Chartbeat, e.g. mysite.com
+	Sections string // comma separated sections
+	Authors  string // comma separated authors
+}
+
+// TrackerCode for Tracker interface
+func (t ChartbeatTracker) TrackerCode(canonical, title string) (string, error) {
+	if t.UID <= 0 {
+		return "", errors.New("Invalid Chartbeat UID " + strconv.Itoa(t.UID))
+	}
+	if t.Domain == "" {
+		return "", errors.New("Chartbeat domain is required")
+	}
+	return `<script>` + iaDocument(canonical, title) +
+		`var _sf_async_config = {uid: ` + strconv.Itoa(t.UID) + `, domain: ` + jsString(t.Domain) +
+		`, useCanonical: true, path: iaURL, title: iaTitle` +
+		`, sections: ` + jsString(t.Sections) + `, authors: ` + jsString(t.Authors) + `};` +
+		`</script>` +
+		`<script async src="https://static.chartbeat.com/js/chartbeat_fia.js"></script>`, nil
+}
+
+// ParselyTracker is Parse.ly tracker
+type ParselyTracker struct {
+	APIKey string // Parse.ly site ID, usually site domain, e.g. mysite.com
+}
+
+// TrackerCode for Tracker interface
+func (t ParselyTracker) TrackerCode(canonical, title string) (string, error) {
+	if !matchAPIKey.MatchString(t.APIKey) {
+		return "", errors.New("Invalid Parse.ly API key " + t.APIKey)
+	}
+	return `<script>` + iaDocument(canonical, title) +
+		`window.PARSELY = {autotrack: false, onload: function() {` +
+		`PARSELY.beacon.trackPageView({url: iaURL, urlref: iaReferrer, js: 1});` +
+		`return true;}};` +
+		`</script>` +
+		`<script id="parsely-cfg" async src="https://cdn.parsely.com/keys/` + t.APIKey + `/p.js"></script>`, nil
+}
+
+// PixelTracker is generic tracking pixel. Article url, referrer and title are
+// appended to pixel URL as url, referrer and title query parameters.
+type PixelTracker struct {
+	URL string // absolute url of tracking pixel
+}
+
+// TrackerCode for Tracker interface
+func (t PixelTracker) TrackerCode(canonical, title string) (string, error) {
+	if !isAbsoluteURL(t.URL) {
+		return "", errors.New("Pixel URL must be absolute " + t.URL)
+	}
+	sep := "?"
+	if strings.Contains(t.URL, "?") {
+		sep = "&"
+	}
+	return `<script>` + iaDocument(canonical, title) +
+		`new Image().src = ` + jsString(t.URL+sep) +
+		` + 'url=' + encodeURIComponent(iaURL)` +
+		` + '&referrer=' + encodeURIComponent(iaReferrer)` +
+		` + '&title=' + encodeURIComponent(iaTitle);` +
+		`</script>`, nil
+}
+
+// iaDocument returns script declaring iaURL, iaReferrer and iaTitle variables.
+// Values from ia_document environment are used when available.
+func iaDocument(canonical, title string) string {
+	return `var ia = window.ia_document || {};` +
+		`var iaURL = ia.shareURL || ` + jsString(canonical) + `;` +
+		`var iaReferrer = ia.referrer || '';` +
+		`var iaTitle = ia.title || ` + jsString(title) + `;`
+}
+
+// jsString returns s as JavaScript string literal safe for use in html script element
+func jsString(s string) string {
+	b, _ := json.Marshal(s)
+	return string(b)
+}
@@ -0,0 +1,74 @@
+package instant_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mileusna/facebook-instant-articles"
+)
+
+func TestAddTracker(t *testing.T) {
+	var a instant.Article
+	a.SetTitle(`My "article" </script>`)
+	a.SetCanonical("http://mysite/url-to-this-article")
+	a.SetPublish(time.Now())
+	a.AddParagraph("Plain text")
+
+	for _, tr := range []instant.Tracker{
+		instant.GA4Tracker{MeasurementID: "G-ABC123"},
+		instant.ComScoreTracker{ClientID: "123456"},
+		instant.ChartbeatTracker{UID: 1234, Domain: "mysite.com", Authors: "Michael"},
+		instant.ParselyTracker{APIKey: "mysite.com"},
+		instant.PixelTracker{URL: "https://pixel.mysite/p.gif?site=1"},
+	} {
+		if err := a.AddTracker(tr); err != nil {
+			t.Fatalf("%T: %v", tr, err)
+		}
+	}
+	if ds := a.Validate(); len(ds) != 0 {
+		t.Errorf("unexpected diagnostics %v", ds)
+	}
+
+	html, err := a.HTML()
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := string(html)
+	if n := strings.Count(s, `<figure class="op-tracker">`); n != 5 {
+		t.Errorf("expected 5 trackers, got %d in %s", n, s)
+	}
+	for _, c := range []string{
+		`var iaURL = ia.shareURL || "http://mysite/url-to-this-article";`,
+		`var iaReferrer = ia.referrer || '';`,
+		`var iaTitle = ia.title || "My \"article\" \u003c/script\u003e";`,
+		`https://www.googletagmanager.com/gtag/js?id=G-ABC123`,
+		`c2: "123456"`,
+		`uid: 1234, domain: "mysite.com"`,
+		`https://cdn.parsely.com/keys/mysite.com/p.js`,
+		`new Image().src = "https://pixel.mysite/p.gif?site=1\u0026" + 'url='`,
+	} {
+		if !strings.Contains(s, c) {
+			t.Errorf("%s not found in %s", c, s)
+		}
+	}
+	if strings.Contains(s, "</script>\";") {
+		t.Errorf("title not escaped in %s", s)
+	}
+}
+
+func TestTrackerErrors(t *testing.T) {
+	for _, tr := range []instant.Tracker{
+		instant.GA4Tracker{MeasurementID: "UA-22233-16"},
+		instant.ComScoreTracker{ClientID: "abc"},
+		instant.ChartbeatTracker{Domain: "mysite.com"},
+		instant.ChartbeatTracker{UID: 1234},
+		instant.ParselyTracker{APIKey: `mysite.com"`},
+		instant.PixelTracker{URL: "/p.gif"},
+	} {
+		var a instant.Article
+		if err := a.AddTracker(tr); err == nil {
+			t.Errorf("expected error for %#v", tr)
+		}
+	}
+}
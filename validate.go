@@ -0,0 +1,467 @@
+package instant
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Severity of validation diagnostic
+type Severity int
+
+// Diagnostic severities
+const (
+	// SeverityError means Facebook will reject the article
+	SeverityError Severity = iota
+	// SeverityWarning means article will be published, but may not be displayed as expected
+	SeverityWarning
+)
+
+// Validation rule IDs reported in Diagnostic.Rule
+const (
+	RuleTitleRequired      = "title-required"
+	RuleCanonicalRequired  = "canonical-required"
+	RuleCanonicalAbsolute  = "canonical-absolute"
+	RuleLangInvalid        = "lang-invalid"
+	RulePublishRequired    = "publish-required"
+	RuleTimeDuplicate      = "time-duplicate"
+	RuleKickerLength       = "kicker-length"
+	RuleParagraphEmpty     = "paragraph-empty"
+	RuleFigureMedia        = "figure-media"
+	RuleAdSize             = "ad-size"
+	RuleAdPlacement        = "ad-placement"
+	RuleTrackerEmpty       = "tracker-empty"
+	RuleAuthorNameRequired = "author-name-required"
+	RuleListEmpty          = "list-empty"
+	RuleHeadingEmpty       = "heading-empty"
+	RuleQuoteEmpty         = "quote-empty"
+	RuleSlideshowEmpty     = "slideshow-empty"
+	RuleCaptionClass       = "caption-class"
+	RuleFigureMode         = "figure-mode"
+	RuleFigureFeedback     = "figure-feedback"
+	RuleVideoSource        = "video-source"
+	RuleMapEmpty           = "map-empty"
+	RuleGeoCoordinates     = "geo-coordinates"
+	RuleGeotagEmpty        = "geotag-empty"
+	RuleEmbedEmpty         = "embed-empty"
+	RuleEmbedHTML          = "embed-html"
+	RuleEmbedClass         = "embed-class"
+	RuleEmbedSize          = "embed-size"
+	RuleAudioSource        = "audio-source"
+	RuleRelatedCount       = "related-count"
+	RuleRelatedURL         = "related-url"
+	RuleSponsorURL         = "sponsor-url"
+	RuleOptionsInvalid     = "options-invalid"
+	RuleAdMixed            = "ad-mixed"
+)
+
+// MaxKickerLength is recommended maximum length of article kicker in characters
+const MaxKickerLength = 60
+
+var matchLang = regexp.MustCompile("^[a-z]{2}(?:[-_][A-Za-z]{2})?$")
+
+// String returns severity name
+func (s Severity) String() string {
+	switch s {
+	case SeverityError:
+		return "error"
+	case SeverityWarning:
+		return "warning"
+	}
+	return fmt.Sprintf("severity(%d)", int(s))
+}
+
+// Diagnostic describes single problem found in article by Validate.
+type Diagnostic struct {
+	Rule     string   // rule ID, one of Rule... constants
+	Severity Severity // SeverityError or SeverityWarning
+	Path     string   // path of element within article, e.g. header.h1 or content[3]
+	Message  string
+}
+
+// Error for error interface
+func (d Diagnostic) Error() string {
+	return d.Path + ": " + d.Message + " (" + d.Rule + ")"
+}
+
+// Diagnostics is list of problems found by Validate.
+type Diagnostics []Diagnostic
+
+// Errors returns only diagnostics with SeverityError.
+func (ds Diagnostics) Errors() Diagnostics {
+	var errs Diagnostics
+	for _, d := range ds {
+		if d.Severity == SeverityError {
+			errs = append(errs, d)
+		}
+	}
+	return errs
+}
+
+// Error for error interface
+func (ds Diagnostics) Error() string {
+	s := make([]string, len(ds))
+	for i, d := range ds {
+		s[i] = d.Error()
+	}
+	return strings.Join(s, "; ")
+}
+
+// Validate checks article against Facebook instant articles specification and returns
+// list of found problems. Article with no SeverityError diagnostics should be accepted by Facebook.
+// Validate can be used before marshaling to give feedback to editors.
+func (a *Article) Validate() Diagnostics {
+	v := validator{diagnostics: a.required()}
+	v.head(a)
+	v.header(&a.Body.Article.Header)
+	v.content("content", a.Body.Article.Content)
+	v.footer(&a.Body.Article.Footer)
+	v.ads(a)
+	return v.diagnostics
+}
+
+// required returns diagnostics for elements required by MarshalXML
+func (a *Article) required() Diagnostics {
+	var ds Diagnostics
+	if a.Body.Article.Header.H1 == "" {
+		ds = append(ds, Diagnostic{RuleTitleRequired, SeverityError, "header.h1", "Article title <h1> is required"})
+	}
+	if a.Head.Link.Href == "" {
+		ds = append(ds, Diagnostic{RuleCanonicalRequired, SeverityError, "head.link", "Canonical link is required"})
+	}
+	return ds
+}
+
+// validator collects diagnostics
+type validator struct {
+	diagnostics Diagnostics
+}
+
+func (v *validator) add(rule string, severity Severity, path, format string, args ...interface{}) {
+	v.diagnostics = append(v.diagnostics, Diagnostic{
+		Rule:     rule,
+		Severity: severity,
+		Path:     path,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+func (v *validator) head(a *Article) {
+	if href := a.Head.Link.Href; href != "" && !isAbsoluteURL(href) {
+		v.add(RuleCanonicalAbsolute, SeverityError, "head.link", "canonical link %q must be absolute http or https URL", href)
+	}
+	if err := a.options().check(); err != nil {
+		v.add(RuleOptionsInvalid, SeverityError, "head.meta", "%v", err)
+	}
+	if a.Lang != "" && !matchLang.MatchString(a.Lang) {
+		v.add(RuleLangInvalid, SeverityError, "html.lang", "invalid language code %q, two-chars language code expected", a.Lang)
+	}
+}
+
+func (v *validator) header(h *header) {
+	times := map[string]int{}
+	for i, t := range h.Time {
+		if n := times[t.Class]; n > 0 {
+			v.add(RuleTimeDuplicate, SeverityError, fmt.Sprintf("header.time[%d]", i), "duplicate %s time", t.Class)
+		}
+		times[t.Class]++
+	}
+	if times["op-published"] == 0 {
+		v.add(RulePublishRequired, SeverityError, "header.time", "publish time is required")
+	}
+
+	if h.H3 != nil && len([]rune(h.H3.Text)) > MaxKickerLength {
+		v.add(RuleKickerLength, SeverityWarning, "header.h3", "kicker is longer than %d characters", MaxKickerLength)
+	}
+
+	for i, addr := range h.Address {
+		if strings.TrimSpace(addr.A.Text) == "" {
+			v.add(RuleAuthorNameRequired, SeverityError, fmt.Sprintf("header.address[%d]", i), "author name is required")
+		}
+	}
+
+	v.content("header.figure", h.Figure)
+
+	if h.Sponsors != nil {
+		if len(h.Sponsors.Pages) == 0 {
+			v.add(RuleSponsorURL, SeverityError, "header.ul", "sponsors list is empty")
+		}
+		for i, p := range h.Sponsors.Pages {
+			if !isFacebookPage(p) {
+				v.add(RuleSponsorURL, SeverityError, fmt.Sprintf("header.ul.li[%d]", i), "sponsor %q must be Facebook Page URL", p)
+			}
+		}
+	}
+}
+
+func (v *validator) ads(a *Article) {
+	for i, t := range a.Body.Article.Header.Figure {
+		if isAd(t) && !a.Options.AutomaticAds {
+			v.add(RuleAdMixed, SeverityError, fmt.Sprintf("header.figure[%d]", i), "ad in header requires automatic ad placement")
+		}
+	}
+	for i, t := range a.Body.Article.Content {
+		if isAd(t) && a.Options.AutomaticAds {
+			v.add(RuleAdMixed, SeverityError, fmt.Sprintf("content[%d]", i), "ad placed in content can't be mixed with automatic ad placement")
+		}
+	}
+}
+
+func (v *validator) audienceNetworkAd(path string, f *IFrame) {
+	u, err := url.Parse(f.Src)
+	if err != nil || u.Host != "www.facebook.com" || u.Path != "/adnw_request" {
+		return
+	}
+	q := u.Query()
+	if !isPlacementID(q.Get("placement")) {
+		v.add(RuleAdPlacement, SeverityError, path, "invalid Audience Network placement ID %q", q.Get("placement"))
+	}
+	size, ok := adTypeSizes[q.Get("adtype")]
+	if !ok {
+		v.add(RuleAdPlacement, SeverityError, path, "unknown Audience Network ad type %q", q.Get("adtype"))
+		return
+	}
+	if f.Width != strconv.Itoa(size.Width) || f.Height != strconv.Itoa(size.Height) {
+		v.add(RuleAdSize, SeverityWarning, path, "ad size %sx%s doesn't match ad type %s", f.Width, f.Height, q.Get("adtype"))
+	}
+}
+
+func (v *validator) footer(f *footer) {
+	if f.Related == nil {
+		return
+	}
+	if n := len(f.Related.Articles); n == 0 || n > MaxRelatedArticles {
+		v.add(RuleRelatedCount, SeverityError, "footer.ul", "footer must have 1 to %d related articles, has %d", MaxRelatedArticles, n)
+	}
+	v.related("footer.ul", f.Related)
+}
+
+func (v *validator) related(path string, r *RelatedArticles) {
+	for i, ra := range r.Articles {
+		if !isAbsoluteURL(ra.URL) {
+			v.add(RuleRelatedURL, SeverityError, fmt.Sprintf("%s.li[%d]", path, i), "related article link %q must be absolute http or https URL", ra.URL)
+		}
+	}
+}
+
+func (v *validator) content(prefix string, c Content) {
+	for i, t := range c {
+		path := fmt.Sprintf("%s[%d]", prefix, i)
+		switch t := t.(type) {
+		case P:
+			v.paragraph(path, t)
+		case *P:
+			v.paragraph(path, *t)
+		case Figure:
+			v.figure(path, &t)
+		case *Figure:
+			v.figure(path, t)
+		case Heading:
+			if strings.TrimSpace(t.Text) == "" {
+				v.add(RuleHeadingEmpty, SeverityError, path, "heading is empty")
+			}
+		case Slideshow:
+			v.slideshow(path, t)
+		case Map:
+			if len(t.Geotag.Locations) == 0 {
+				v.add(RuleMapEmpty, SeverityError, path, "map has no locations")
+			}
+			v.geotag(path+".script", &t.Geotag)
+			v.caption(path+".figcaption", t.Figcaption)
+		case RelatedArticles:
+			if len(t.Articles) == 0 {
+				v.add(RuleRelatedCount, SeverityError, path, "related articles list is empty")
+			}
+			v.related(path, &t)
+		case Blockquote:
+			v.quote(path, t.Text)
+		case PullQuote:
+			v.quote(path, t.Text)
+		case UL:
+			v.list(path, t.LI)
+		case OL:
+			v.list(path, t.LI)
+		}
+	}
+}
+
+func (v *validator) paragraph(path string, p P) {
+	if strings.TrimSpace(p.Text) == "" {
+		v.add(RuleParagraphEmpty, SeverityError, path, "paragraph is empty")
+	}
+}
+
+func (v *validator) slideshow(path string, s Slideshow) {
+	v.figureGeotag(path+".script", s.Geotag)
+	v.caption(path+".figcaption", s.Figcaption)
+	if len(s.Figure) == 0 {
+		v.add(RuleSlideshowEmpty, SeverityError, path, "slideshow has no images")
+	}
+	for i, f := range s.Figure {
+		p := fmt.Sprintf("%s.figure[%d]", path, i)
+		if f.Img == nil {
+			v.add(RuleFigureMedia, SeverityError, p, "slideshow figure has no image")
+		}
+		v.caption(p+".figcaption", f.Figcaption)
+	}
+}
+
+func (v *validator) quote(path, text string) {
+	if strings.TrimSpace(text) == "" {
+		v.add(RuleQuoteEmpty, SeverityError, path, "quote is empty")
+	}
+}
+
+func (v *validator) list(path string, items []LI) {
+	if len(items) == 0 {
+		v.add(RuleListEmpty, SeverityError, path, "list has no items")
+	}
+	for i, li := range items {
+		if strings.TrimSpace(li.Text) == "" {
+			v.add(RuleListEmpty, SeverityError, fmt.Sprintf("%s.li[%d]", path, i), "list item is empty")
+		}
+	}
+}
+
+func (v *validator) figure(path string, f *Figure) {
+	switch f.Class {
+	case "op-ad":
+		if f.IFrame == nil || !isPositive(f.IFrame.Width) || !isPositive(f.IFrame.Height) {
+			v.add(RuleAdSize, SeverityError, path, "ad must have width and height")
+		}
+		if f.IFrame != nil {
+			v.audienceNetworkAd(path, f.IFrame)
+		}
+		return
+	case "op-tracker":
+		if f.IFrame == nil || f.IFrame.Src == "" && strings.TrimSpace(f.IFrame.Text) == "" {
+			v.add(RuleTrackerEmpty, SeverityError, path, "tracker has no url or code")
+		}
+		return
+	case "op-social", "op-interactive":
+		if f.IFrame == nil || f.IFrame.Src == "" && strings.TrimSpace(f.IFrame.Text) == "" {
+			v.add(RuleEmbedEmpty, SeverityError, path, "embed has no url or code")
+		} else if f.Class == "op-interactive" {
+			v.interactive(path+".iframe", f.IFrame)
+		}
+		v.caption(path+".figcaption", f.Figcaption)
+		return
+	}
+	if f.Img == nil && f.Video == nil && f.IFrame == nil && f.Audio == nil {
+		v.add(RuleFigureMedia, SeverityError, path, "figure has no image, video, audio or iframe")
+	}
+	if f.Video != nil {
+		v.video(path+".video", f.Video)
+	}
+	if f.Audio != nil {
+		v.audio(path+".audio", f.Audio)
+	}
+	v.figureGeotag(path+".script", f.Geotag)
+	switch f.Mode {
+	case "", ModeFullscreen, ModeAspectFit, ModeAspectFitOnly, ModeNonInteractive:
+	default:
+		v.add(RuleFigureMode, SeverityWarning, path, "unknown presentation mode %q", f.Mode)
+	}
+	switch f.Feedback {
+	case "", "fb:likes", "fb:comments", "fb:likes,fb:comments":
+	default:
+		v.add(RuleFigureFeedback, SeverityWarning, path, "unknown feedback %q", f.Feedback)
+	}
+	v.caption(path+".figcaption", f.Figcaption)
+}
+
+func (v *validator) interactive(path string, f *IFrame) {
+	if f.Text != "" {
+		if err := checkInlineHTML(f.Text); err != nil {
+			v.add(RuleEmbedHTML, SeverityError, path, "%v", err)
+		}
+	}
+	switch f.Class {
+	case "", "no-margin", "column-width":
+	default:
+		v.add(RuleEmbedClass, SeverityWarning, path, "unknown interactive class %q", f.Class)
+	}
+	if f.Width != "" && !isPositive(f.Width) || f.Height != "" && !isPositive(f.Height) {
+		v.add(RuleEmbedSize, SeverityWarning, path, "width and height must be positive integers")
+	}
+}
+
+func (v *validator) video(path string, video *Video) {
+	if len(video.Source) == 0 {
+		v.add(RuleVideoSource, SeverityError, path, "video has no source")
+	}
+	for i, s := range video.Source {
+		if s.Src == "" {
+			v.add(RuleVideoSource, SeverityError, fmt.Sprintf("%s.source[%d]", path, i), "video source has no url")
+		}
+		if !strings.HasPrefix(s.Type, "video/") {
+			v.add(RuleVideoSource, SeverityWarning, fmt.Sprintf("%s.source[%d]", path, i), "invalid video type %q", s.Type)
+		}
+	}
+}
+
+func (v *validator) figureGeotag(path string, g *Geotag) {
+	if g != nil && len(g.Locations) == 0 {
+		v.add(RuleGeotagEmpty, SeverityError, path, "geotag has no locations")
+	}
+	v.geotag(path, g)
+}
+
+func (v *validator) geotag(path string, g *Geotag) {
+	if g == nil {
+		return
+	}
+	for i, l := range g.Locations {
+		if !validCoordinates(l.Latitude, l.Longitude) {
+			v.add(RuleGeoCoordinates, SeverityError, path, "location %d has invalid coordinates [%g, %g]", i, l.Latitude, l.Longitude)
+		}
+	}
+}
+
+func (v *validator) audio(path string, a *Audio) {
+	if len(a.Source) == 0 {
+		v.add(RuleAudioSource, SeverityError, path, "audio has no source")
+	}
+	for i, s := range a.Source {
+		if s.Src == "" {
+			v.add(RuleAudioSource, SeverityError, fmt.Sprintf("%s.source[%d]", path, i), "audio source has no url")
+		}
+		if !strings.HasPrefix(s.Type, "audio/") {
+			v.add(RuleAudioSource, SeverityWarning, fmt.Sprintf("%s.source[%d]", path, i), "invalid audio type %q", s.Type)
+		}
+	}
+}
+
+func (v *validator) caption(path string, c *Caption) {
+	if c == nil {
+		return
+	}
+	check := func(value string, valid ...string) {
+		if value == "" {
+			return
+		}
+		for _, s := range valid {
+			if value == s {
+				return
+			}
+		}
+		v.add(RuleCaptionClass, SeverityWarning, path, "unknown caption class %q", value)
+	}
+	check(c.Position, CaptionVerticalTop, CaptionVerticalCenter, CaptionVerticalBottom)
+	check(c.Align, CaptionAlignLeft, CaptionAlignCenter, CaptionAlignRight)
+	check(c.Size, CaptionSmall, CaptionMedium, CaptionLarge, CaptionExtraLarge)
+}
+
+// isAbsoluteURL reports if s is absolute http or https URL
+func isAbsoluteURL(s string) bool {
+	u, err := url.Parse(s)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+// isPositive reports if s is positive integer
+func isPositive(s string) bool {
+	n, err := strconv.Atoi(s)
+	return err == nil && n > 0
+}
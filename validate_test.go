@@ -0,0 +1,54 @@
+package instant_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mileusna/facebook-instant-articles"
+)
+
+func TestValidate(t *testing.T) {
+	var a instant.Article
+	a.SetCanonical("/relative-url")
+	a.SetLang("english")
+	a.SetModified(time.Now())
+	a.SetModified(time.Now())
+	a.SetKick("This kicker is way too long to be displayed properly above the article title")
+	a.AddParagraph(" ")
+	a.AddFigure(instant.Figure{Figcaption: &instant.Caption{Text: "No media"}})
+	a.InsertAd(0, "http://mysite/ad", 0, 50, "", "")
+
+	rules := map[string]instant.Severity{}
+	for _, d := range a.Validate() {
+		rules[d.Rule] = d.Severity
+	}
+
+	expected := map[string]instant.Severity{
+		instant.RuleTitleRequired:     instant.SeverityError,
+		instant.RuleCanonicalAbsolute: instant.SeverityError,
+		instant.RuleLangInvalid:       instant.SeverityError,
+		instant.RulePublishRequired:   instant.SeverityError,
+		instant.RuleTimeDuplicate:     instant.SeverityError,
+		instant.RuleKickerLength:      instant.SeverityWarning,
+		instant.RuleParagraphEmpty:    instant.SeverityError,
+		instant.RuleFigureMedia:       instant.SeverityError,
+		instant.RuleAdSize:            instant.SeverityError,
+	}
+	for rule, severity := range expected {
+		if s, ok := rules[rule]; !ok || s != severity {
+			t.Errorf("expected %s %s", severity, rule)
+		}
+	}
+	if len(rules) != len(expected) {
+		t.Errorf("unexpected diagnostics %v", rules)
+	}
+
+	a = instant.Article{}
+	a.SetTitle("My article title")
+	a.SetCanonical("http://mysite/url-to-this-article")
+	a.SetPublish(time.Now())
+	a.SetContent("<p>My content</p>")
+	if ds := a.Validate(); len(ds) != 0 {
+		t.Errorf("unexpected diagnostics %v", ds)
+	}
+}